@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"Blog/usecases"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminStatsController struct {
+	statsUsecase *usecases.AdminStatsUsecase
+}
+
+func NewAdminStatsController(u *usecases.AdminStatsUsecase) *AdminStatsController {
+	return &AdminStatsController{statsUsecase: u}
+}
+
+// site-wide activity for the last ?days= days (default 30, max 365)
+func (ctrl *AdminStatsController) GetStats(c *gin.Context) {
+	days := 0
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		days = n
+	}
+
+	stats, err := ctrl.statsUsecase.GetStats(days)
+	if errors.Is(err, domain.ErrInvalidStatsRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
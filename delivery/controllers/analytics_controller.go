@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsController struct {
+	analyticsUsecase *usecases.AnalyticsUsecase
+}
+
+func NewAnalyticsController(u *usecases.AnalyticsUsecase) *AnalyticsController {
+	return &AnalyticsController{analyticsUsecase: u}
+}
+
+// interaction time series for a blog (?from=&to=&granularity=hour|day), author or admin only
+func (ctrl *AnalyticsController) GetBlogStats(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := ctrl.analyticsUsecase.GetBlogStats(userID, c.Param("id"), c.Query("from"), c.Query("to"), c.Query("granularity"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -3,7 +3,9 @@ package controllers
 import (
 	"Blog/domain"
 	"Blog/usecases"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,11 +13,37 @@ import (
 )
 
 type BlogController struct {
-	blogUsecase *usecases.BlogUsecase
+	blogUsecase     *usecases.BlogUsecase
+	bookmarkUsecase *usecases.BookmarkUsecase
+	mediaUsecase    *usecases.MediaUsecase
 }
 
-func NewTaskController(u *usecases.BlogUsecase) *BlogController {
-	return &BlogController{blogUsecase: u}
+func NewTaskController(u *usecases.BlogUsecase, bookmarks *usecases.BookmarkUsecase, media *usecases.MediaUsecase) *BlogController {
+	return &BlogController{blogUsecase: u, bookmarkUsecase: bookmarks, mediaUsecase: media}
+}
+
+// helper to flag which blogs the signed-in reader has bookmarked
+func (ctrl *BlogController) markBookmarked(c *gin.Context, blogs ...*domain.Blog) {
+	userID, _ := c.Get("userID")
+	viewer, _ := userID.(string)
+	if viewer != "" && ctrl.bookmarkUsecase != nil {
+		ctrl.bookmarkUsecase.MarkBookmarked(viewer, blogs...)
+	}
+}
+
+// helper to fill in the media files blogs reference
+func (ctrl *BlogController) attachMedia(blogs ...*domain.Blog) {
+	if ctrl.mediaUsecase != nil {
+		ctrl.mediaUsecase.AttachMedia(blogs...)
+	}
+}
+
+// helper for create and update: bad media references are the client's fault, anything else is ours
+func blogWriteStatus(err error) int {
+	if errors.Is(err, domain.ErrMediaNotFound) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
 }
 
 // create a blog
@@ -42,6 +70,7 @@ func (ctrl *BlogController) CreateBlog(c *gin.Context) {
 		Title   string             `json:"title"`
 		Content string             `json:"content"`
 		Tags    []string           `json:"tags"`
+		MediaIDs []primitive.ObjectID `json:"media_ids"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -56,19 +85,22 @@ func (ctrl *BlogController) CreateBlog(c *gin.Context) {
 		Content:     input.Content,
 		UserID:      userId,
 		Tags:        input.Tags,
+		MediaIDs:    input.MediaIDs,
 		Likes:       0,                      
 		Dislikes:    0,                      
 		ViewCount:   0,                      
 		DateCreated: time.Now(),            
+		Status:      domain.BlogStatusPublished,
 	}
 
 
 	CreatedBlog, err := ctrl.blogUsecase.CreateBlog(&blog)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(blogWriteStatus(err), gin.H{"error": err.Error()})
 	    return
 	}
+	ctrl.attachMedia(CreatedBlog)
 
 	c.JSON(http.StatusCreated, gin.H{"message": "blog created successfully", "Created_Blog": CreatedBlog})
 
@@ -77,7 +109,11 @@ func (ctrl *BlogController) CreateBlog(c *gin.Context) {
 // get a blog by id
 func(ctrl *BlogController) GetBlog(c *gin.Context) {
 	id := c.Param("id")
-	blog, err := ctrl.blogUsecase.GetBlogByID(id)
+	viewer := domain.Viewer{IP: c.ClientIP(), UserAgent: c.Request.UserAgent(), Referrer: c.Request.Referer()}
+	if userID, ok := c.Get("userID"); ok {
+		viewer.UserID, _ = userID.(string)
+	}
+	blog, err := ctrl.blogUsecase.GetBlogByID(id, viewer)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "blog not found"})
         return
@@ -86,42 +122,48 @@ func(ctrl *BlogController) GetBlog(c *gin.Context) {
 	if blog == nil {
 			c.JSON(http.StatusOK, gin.H{"message": "Blog not found"})
 	}
+	ctrl.markBookmarked(c, blog)
+	ctrl.attachMedia(blog)
 	c.JSON(http.StatusOK, blog)
 } 
 
 // get all blogs
 func(ctrl *BlogController) GetBlogs(c *gin.Context) {
-	blogs, err := ctrl.blogUsecase.GetAllBlogs()
-
-	if err != nil {
-	    c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, ok := pageRequest(c)
+	if !ok {
 		return
 	}
 
-	if len(blogs) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No blogs posted"})
+	blogs, err := ctrl.blogUsecase.GetAllBlogs(page)
+
+	if err != nil {
+	    c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
+	ctrl.markBookmarked(c, blogs.Items...)
+	ctrl.attachMedia(blogs.Items...)
 	c.JSON(http.StatusOK, blogs)
 }
 
 // update a blog
 func (ctrl *BlogController) UpdateBlog(c *gin.Context) {
 	id := c.Param("id")
-	var blog domain.Blog
+	// only title, content, tags and media_ids can be edited; anything else in the body is ignored
+	var update domain.BlogUpdate
 
-	if err := c.ShouldBindJSON(&blog); err != nil {
+	if err := c.ShouldBindJSON(&update); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	UpdatedBlog, err := ctrl.blogUsecase.UpdateBlog(id, &blog)
+	UpdatedBlog, err := ctrl.blogUsecase.UpdateBlog(id, update)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(blogWriteStatus(err), gin.H{"error": err.Error()})
 	    return
 	}
+	ctrl.attachMedia(UpdatedBlog)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":      "Blog updated successfully",
@@ -196,47 +238,153 @@ func (ctrl *BlogController) DislikeBlog(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Blog disliked"})
 }
 
-// Filter blogs
-func (ctrl *BlogController) FilterBlogs(c *gin.Context) {
-	var body struct {
-		Tags   []string `json:"tags"`
-		Start  string   `json:"start"` // ISO8601
-		End    string   `json:"end"`   // ISO8601
-		SortBy string   `json:"sort_by"`
+// withdraw the user's like
+func (ctrl *BlogController) UnlikeBlog(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
 	}
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+	if err := ctrl.blogUsecase.UnlikeBlog(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Like removed"})
+}
+
+// withdraw the user's dislike
+func (ctrl *BlogController) UndislikeBlog(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	var start *time.Time
-	var end *time.Time
-	if body.Start != "" {
-		st, err := time.Parse(time.RFC3339, body.Start)
+	if err := ctrl.blogUsecase.UndislikeBlog(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Dislike removed"})
+}
+
+// Filter blogs with a filter tree (JSON body) or filter expression (?filter= or "query")
+func (ctrl *BlogController) FilterBlogs(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	sortBy := c.Query("sort_by")
+
+	var filters []*domain.BlogFilter
+	if expr := c.Query("filter"); expr != "" {
+		f, err := usecases.ParseBlogFilter(expr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start date"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		start = &st
+		filters = append(filters, f)
 	}
-	if body.End != "" {
-		en, err := time.Parse(time.RFC3339, body.End)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end date"})
+
+	if c.Request.Method == http.MethodPost {
+		var body struct {
+			Filter *domain.BlogFilter `json:"filter"`
+			Query  string             `json:"query"`
+			Tags   []string           `json:"tags"`
+			Start  string             `json:"start"` // ISO8601
+			End    string             `json:"end"`   // ISO8601
+			SortBy string             `json:"sort_by"`
+			Limit  int                `json:"limit"`
+			Cursor string             `json:"cursor"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if body.Filter != nil {
+			filters = append(filters, body.Filter)
+		}
+		if body.Query != "" {
+			f, err := usecases.ParseBlogFilter(body.Query)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			filters = append(filters, f)
+		}
+
+		// the original tags/start/end fields still work
+		legacy := &domain.BlogFilter{}
+		if len(body.Tags) > 0 {
+			legacy.Tags = &domain.TagCondition{All: body.Tags}
+		}
+		if body.Start != "" {
+			st, err := time.Parse(time.RFC3339, body.Start)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start date"})
+				return
+			}
+			legacy.From = &st
+		}
+		if body.End != "" {
+			en, err := time.Parse(time.RFC3339, body.End)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end date"})
+				return
+			}
+			legacy.To = &en
+		}
+		if legacy.Tags != nil || legacy.From != nil || legacy.To != nil {
+			filters = append(filters, legacy)
+		}
+
+		if body.SortBy != "" {
+			sortBy = body.SortBy
+		}
+		if body.Limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
 			return
 		}
-		end = &en
+		if body.Limit > 0 {
+			page.Limit = body.Limit
+		}
+		if body.Cursor != "" {
+			page.Cursor = body.Cursor
+		}
 	}
 
-	blogs, err := ctrl.blogUsecase.FilterBlogs(body.Tags, start, end, body.SortBy)
+	filter := &domain.BlogFilter{}
+	if len(filters) == 1 {
+		filter = filters[0]
+	} else if len(filters) > 1 {
+		filter.And = filters
+	}
+
+	viewerID, _ := c.Get("userID")
+	viewer, _ := viewerID.(string)
+
+	blogs, err := ctrl.blogUsecase.FilterBlogs(viewer, filter, sortBy, page.Normalize())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, domain.ErrInvalidFilter):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "only admins can filter by status"})
+		default:
+			c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		}
 		return
 	}
+	ctrl.markBookmarked(c, blogs.Items...)
+	ctrl.attachMedia(blogs.Items...)
 	c.JSON(http.StatusOK, blogs)
 }
 
 func (ctrl *BlogController) SuggestBlog(c *gin.Context) {
+    userID, ok := currentUserID(c)
+    if !ok {
+        return
+    }
+
     var req struct {
         Prompt string `json:"prompt"`
     }
@@ -245,7 +393,7 @@ func (ctrl *BlogController) SuggestBlog(c *gin.Context) {
         return
     }
 
-    suggestion, err := ctrl.blogUsecase.SuggestContent(req.Prompt)
+    suggestion, err := ctrl.blogUsecase.SuggestContent(userID, req.Prompt)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
@@ -254,27 +402,85 @@ func (ctrl *BlogController) SuggestBlog(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"suggestion": suggestion})
 }
 
-// blog_controller.go
-
+// search blogs by relevance across title, content, tags and author (?q= or a JSON body)
 func (ctrl *BlogController) SearchBlog(c *gin.Context) {
-    var body struct {
-        Title string `json:"title"`
-    }
-    if err := c.ShouldBindJSON(&body); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	query := c.Query("q")
+
+	if c.Request.Method == http.MethodPost {
+		var body struct {
+			Query  string `json:"query"`
+			Title  string `json:"title"` // accepted for older clients
+			Limit  int    `json:"limit"`
+			Cursor string `json:"cursor"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query = body.Query
+		if query == "" {
+			query = body.Title
+		}
+		if body.Limit > 0 {
+			page.Limit = body.Limit
+		}
+		if body.Cursor != "" {
+			page.Cursor = body.Cursor
+		}
+	}
 
-    if body.Title == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
-        return
-    }
+	hits, err := ctrl.blogUsecase.SearchBlogs(query, page.Normalize())
+	if err != nil {
+		if errors.Is(err, domain.ErrEmptySearchQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
 
-    blog, err := ctrl.blogUsecase.SearchBlog(body.Title)
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
-        return
-    }
+	blogs := make([]*domain.Blog, len(hits.Items))
+	for i, hit := range hits.Items {
+		blogs[i] = hit.Blog
+	}
+	ctrl.markBookmarked(c, blogs...)
+	ctrl.attachMedia(blogs...)
+	c.JSON(http.StatusOK, hits)
+}
+
+// autocomplete a partial search query (?q=)
+func (ctrl *BlogController) SuggestSearch(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	words, err := ctrl.blogUsecase.SuggestSearch(c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"suggestions": words})
+}
+
+// trending posts for a window (?window=day|week|month)
+func (ctrl *BlogController) GetTrending(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
 
-    c.JSON(http.StatusOK, blog)
+	blogs, err := ctrl.blogUsecase.GetTrending(c.Query("window"), page)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidTrendingWindow) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctrl.markBookmarked(c, blogs.Items...)
+	ctrl.attachMedia(blogs.Items...)
+	c.JSON(http.StatusOK, blogs)
 }
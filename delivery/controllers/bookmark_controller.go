@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkController struct {
+	bookmarkUsecase *usecases.BookmarkUsecase
+}
+
+func NewBookmarkController(u *usecases.BookmarkUsecase) *BookmarkController {
+	return &BookmarkController{bookmarkUsecase: u}
+}
+
+// bookmark a blog for later
+func (ctrl *BookmarkController) AddBookmark(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.bookmarkUsecase.AddBookmark(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bookmarked": true})
+}
+
+// remove a bookmark
+func (ctrl *BookmarkController) RemoveBookmark(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.bookmarkUsecase.RemoveBookmark(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bookmarked": false})
+}
+
+// list the user's bookmarks, newest first
+func (ctrl *BookmarkController) GetBookmarks(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	bookmarks, err := ctrl.bookmarkUsecase.GetBookmarks(userID, page)
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, bookmarks)
+}
+
+// create a reading list
+func (ctrl *BookmarkController) CreateList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := ctrl.bookmarkUsecase.CreateList(userID, req.Name, req.Description, req.Public)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, list)
+}
+
+// list the user's own reading lists
+func (ctrl *BookmarkController) GetMyLists(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	lists, err := ctrl.bookmarkUsecase.GetLists(userID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lists)
+}
+
+// view a reading list with its posts; private lists only for their owner
+func (ctrl *BookmarkController) GetList(c *gin.Context) {
+	viewerID, _ := c.Get("userID")
+	viewer, _ := viewerID.(string)
+
+	list, err := ctrl.bookmarkUsecase.GetList(viewer, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// rename a list, change its description or make it public/private
+func (ctrl *BookmarkController) UpdateList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Public      *bool   `json:"public"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := ctrl.bookmarkUsecase.UpdateList(userID, c.Param("id"), req.Name, req.Description, req.Public)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// delete a reading list
+func (ctrl *BookmarkController) DeleteList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.bookmarkUsecase.DeleteList(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "reading list deleted"})
+}
+
+// add a blog to a list, at the end unless a position is given
+func (ctrl *BookmarkController) AddToList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		BlogID   string `json:"blog_id" binding:"required"`
+		Position *int   `json:"position"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	position := -1
+	if req.Position != nil && *req.Position >= 0 {
+		position = *req.Position
+	}
+
+	list, err := ctrl.bookmarkUsecase.AddToList(userID, c.Param("id"), req.BlogID, position)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// remove a blog from a list
+func (ctrl *BookmarkController) RemoveFromList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	list, err := ctrl.bookmarkUsecase.RemoveFromList(userID, c.Param("id"), c.Param("blogId"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// reorder a list by sending all of its blog ids in the new order
+func (ctrl *BookmarkController) ReorderList(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		BlogIDs []string `json:"blog_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := ctrl.bookmarkUsecase.ReorderList(userID, c.Param("id"), req.BlogIDs)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
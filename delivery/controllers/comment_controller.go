@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	commentUsecase *usecases.CommentUsecase
+}
+
+func NewCommentController(u *usecases.CommentUsecase) *CommentController {
+	return &CommentController{commentUsecase: u}
+}
+
+// add a comment to a blog
+func (ctrl *CommentController) AddComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Content  string `json:"content" binding:"required"`
+		ParentID string `json:"parent_id"` // set when replying to a comment
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := ctrl.commentUsecase.AddComment(userID, c.Param("id"), input.Content, input.ParentID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "comment submitted", "comment": comment})
+}
+
+// list the approved comments of a blog
+func (ctrl *CommentController) GetComments(c *gin.Context) {
+	comments, err := ctrl.commentUsecase.GetComments(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// open, close or require approval for comments on a blog
+func (ctrl *CommentController) SetCommentMode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Mode string `json:"mode" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.commentUsecase.SetCommentMode(userID, c.Param("id"), input.Mode); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "comment settings updated", "mode": input.Mode})
+}
+
+// list pending comments the current user may moderate
+func (ctrl *CommentController) GetModerationQueue(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	comments, err := ctrl.commentUsecase.GetModerationQueue(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// approve, reject or mark a comment as spam
+func (ctrl *CommentController) ModerateComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Action string `json:"action" binding:"required"` // approve, reject or spam
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.commentUsecase.ModerateComment(userID, c.Param("id"), input.Action); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "comment moderated", "action": input.Action})
+}
+
+func (ctrl *CommentController) GetBlockedWords(c *gin.Context) {
+	words, err := ctrl.commentUsecase.GetBlockedWords()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"words": words})
+}
+
+func (ctrl *CommentController) SetBlockedWords(c *gin.Context) {
+	var input struct {
+		Words []string `json:"words"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.commentUsecase.SetBlockedWords(input.Words); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "blocked words updated"})
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DashboardController struct {
+	dashboardUsecase *usecases.DashboardUsecase
+}
+
+func NewDashboardController(u *usecases.DashboardUsecase) *DashboardController {
+	return &DashboardController{dashboardUsecase: u}
+}
+
+// the signed-in author's writing statistics
+func (ctrl *DashboardController) GetDashboard(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	dashboard, err := ctrl.dashboardUsecase.GetDashboard(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dashboard)
+}
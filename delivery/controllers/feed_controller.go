@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedController struct {
+	feedUsecase     *usecases.FeedUsecase
+	bookmarkUsecase *usecases.BookmarkUsecase
+}
+
+func NewFeedController(u *usecases.FeedUsecase, bookmarks *usecases.BookmarkUsecase) *FeedController {
+	return &FeedController{feedUsecase: u, bookmarkUsecase: bookmarks}
+}
+
+// follow an author
+func (ctrl *FeedController) FollowAuthor(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.feedUsecase.FollowAuthor(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"following": true})
+}
+
+// stop following an author
+func (ctrl *FeedController) UnfollowAuthor(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.feedUsecase.UnfollowAuthor(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"following": false})
+}
+
+// follow a tag
+func (ctrl *FeedController) FollowTag(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.feedUsecase.FollowTag(userID, c.Param("tag")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"following": true})
+}
+
+// stop following a tag
+func (ctrl *FeedController) UnfollowTag(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.feedUsecase.UnfollowTag(userID, c.Param("tag")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"following": false})
+}
+
+// list the authors and tags the user follows
+func (ctrl *FeedController) GetFollowing(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	follows, err := ctrl.feedUsecase.GetFollowing(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, follows)
+}
+
+// the user's home feed: posts from followed authors and tags, newest first
+func (ctrl *FeedController) GetFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	feed, err := ctrl.feedUsecase.GetFeed(userID, page)
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if ctrl.bookmarkUsecase != nil {
+		ctrl.bookmarkUsecase.MarkBookmarked(userID, feed.Items...)
+	}
+	c.JSON(http.StatusOK, feed)
+}
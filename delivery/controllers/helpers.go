@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// helper to read the authenticated user id set by AuthMiddleware, writing the error response when missing
+func currentUserID(c *gin.Context) (string, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return "", false
+	}
+
+	userID, ok := userIDValue.(string)
+	if !ok || userID == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return "", false
+	}
+	return userID, true
+}
+
+// helper to map domain errors onto HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrBlogNotFound), errors.Is(err, domain.ErrCommentNotFound), errors.Is(err, domain.ErrReportNotFound), errors.Is(err, domain.ErrNoReaction),
+		errors.Is(err, domain.ErrReadingListNotFound), errors.Is(err, domain.ErrNotificationNotFound), errors.Is(err, domain.ErrMediaNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAlreadyReported):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// helper to read ?limit= and ?cursor=, capping the limit at domain.MaxPageSize
+func pageRequest(c *gin.Context) (domain.PageRequest, bool) {
+	page := domain.PageRequest{Cursor: c.Query("cursor")}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return page, false
+		}
+		page.Limit = limit
+	}
+	return page.Normalize(), true
+}
+
+// helper for list endpoints: a bad cursor is the client's fault, anything else is ours
+func listErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// helper for cacheable documents: sets ETag and Last-Modified and answers conditional requests with 304
+func writeCacheable(c *gin.Context, contentType string, body []byte, modified time.Time) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match wins over If-Modified-Since when both are sent
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if etagMatches(match, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !modified.IsZero() {
+		if !modified.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// etagMatches checks an If-None-Match list, comparing weakly as the header requires
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
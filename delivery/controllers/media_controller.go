@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"Blog/usecases"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// room for the multipart framing around the file itself
+const multipartOverhead = 1 << 20
+
+type MediaController struct {
+	mediaUsecase *usecases.MediaUsecase
+}
+
+func NewMediaController(u *usecases.MediaUsecase) *MediaController {
+	return &MediaController{mediaUsecase: u}
+}
+
+// helper to map upload errors onto HTTP status codes
+func mediaErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, domain.ErrMediaTooLarge), errors.Is(err, domain.ErrMediaQuotaExceeded), errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return errorStatus(err)
+	}
+}
+
+// upload a file into the caller's media library (multipart form field "file")
+func (ctrl *MediaController) Upload(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ctrl.mediaUsecase.MaxUploadSize()+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(mediaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	media, err := ctrl.mediaUsecase.Upload(userID, header.Filename, file)
+	if err != nil {
+		c.JSON(mediaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, media)
+}
+
+// list the caller's media library, newest first
+func (ctrl *MediaController) GetLibrary(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	library, err := ctrl.mediaUsecase.GetLibrary(userID, page)
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, library)
+}
+
+func (ctrl *MediaController) GetMedia(c *gin.Context) {
+	media, err := ctrl.mediaUsecase.GetMedia(c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, media)
+}
+
+// delete a file from the caller's library; posts using it drop the reference
+func (ctrl *MediaController) DeleteMedia(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.mediaUsecase.DeleteMedia(userID, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "media deleted"})
+}
+
+// serve a stored file; keys never change content, so it may be cached for good
+func (ctrl *MediaController) ServeFile(c *gin.Context) {
+	body, contentType, err := ctrl.mediaUsecase.Open(strings.TrimPrefix(c.Param("key"), "/"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, body)
+}
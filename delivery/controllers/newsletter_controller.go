@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NewsletterController struct {
+	newsletterUsecase *usecases.NewsletterUsecase
+}
+
+func NewNewsletterController(u *usecases.NewsletterUsecase) *NewsletterController {
+	return &NewsletterController{newsletterUsecase: u}
+}
+
+// sign an email address up for digests; nothing is sent until it is confirmed
+func (ctrl *NewsletterController) Subscribe(c *gin.Context) {
+	var req struct {
+		Email    string `json:"email" binding:"required"`
+		Scope    string `json:"scope"` // blog (default), author or tag
+		AuthorID string `json:"author_id"`
+		Tag      string `json:"tag"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ctrl.newsletterUsecase.Subscribe(req.Email, req.Scope, req.AuthorID, req.Tag); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "check your inbox to confirm the subscription"})
+}
+
+// confirm a subscription from the emailed link
+func (ctrl *NewsletterController) Confirm(c *gin.Context) {
+	subscription, err := ctrl.newsletterUsecase.Confirm(c.Query("token"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "subscription confirmed", "subscription": subscription})
+}
+
+// page behind the emailed unsubscribe link; a button posts back to the same address
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Unsubscribe</title></head>
+<body>
+<p>Stop sending digests to {{.Email}}?</p>
+<form method="post" action="?token={{.Token}}"><button type="submit">Unsubscribe</button></form>
+</body>
+</html>
+`))
+
+// the unsubscribe link only asks for confirmation, so link scanners and prefetchers
+// following it change nothing
+func (ctrl *NewsletterController) UnsubscribeForm(c *gin.Context) {
+	token := c.Query("token")
+	subscription, err := ctrl.newsletterUsecase.GetUnsubscribe(token)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		var page bytes.Buffer
+		if err := unsubscribePage.Execute(&page, gin.H{"Email": subscription.Email, "Token": token}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "send a POST to this address to unsubscribe", "subscription": subscription})
+}
+
+// unsubscribe, from the confirmation page or by mail clients posting to the link (RFC 8058 one-click)
+func (ctrl *NewsletterController) Unsubscribe(c *gin.Context) {
+	subscription, err := ctrl.newsletterUsecase.Unsubscribe(c.Query("token"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "you have been unsubscribed", "subscription": subscription})
+}
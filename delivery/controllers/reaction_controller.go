@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReactionController struct {
+	reactionUsecase *usecases.ReactionUsecase
+}
+
+func NewReactionController(u *usecases.ReactionUsecase) *ReactionController {
+	return &ReactionController{reactionUsecase: u}
+}
+
+// list the reactions readers can use
+func (ctrl *ReactionController) GetReactionTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, ctrl.reactionUsecase.Types())
+}
+
+// react to a blog, replacing the user's previous reaction
+func (ctrl *ReactionController) SetReaction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	counts, err := ctrl.reactionUsecase.SetReaction(userID, c.Param("id"), c.Param("type"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reaction": c.Param("type"), "reactions": counts})
+}
+
+// withdraw the user's reaction
+func (ctrl *ReactionController) RemoveReaction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	counts, err := ctrl.reactionUsecase.RemoveReaction(userID, c.Param("id"), c.Param("type"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reactions": counts})
+}
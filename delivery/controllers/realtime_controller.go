@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"Blog/usecases"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keep-alives for proxies and dead-peer detection on long-lived connections
+const (
+	streamHeartbeat      = 25 * time.Second
+	webSocketIdleTimeout = 75 * time.Second
+)
+
+type RealtimeController struct {
+	realtimeUsecase *usecases.RealtimeUsecase
+}
+
+func NewRealtimeController(u *usecases.RealtimeUsecase) *RealtimeController {
+	return &RealtimeController{realtimeUsecase: u}
+}
+
+// helper to open a subscription for the caller and the blogs in ?blogs=id1,id2
+func (ctrl *RealtimeController) subscribe(c *gin.Context) (domain.Subscription, []domain.RealtimeMessage, bool) {
+	userIDValue, _ := c.Get("userID")
+	userID, _ := userIDValue.(string)
+
+	var blogIDs []string
+	for _, id := range strings.Split(c.Query("blogs"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			blogIDs = append(blogIDs, id)
+		}
+	}
+	if userID == "" && len(blogIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sign in or pass ?blogs= to subscribe to"})
+		return nil, nil, false
+	}
+
+	sub, initial, err := ctrl.realtimeUsecase.Subscribe(userID, blogIDs)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	return sub, initial, true
+}
+
+// stream live reaction counts and the caller's notifications as Server-Sent Events
+func (ctrl *RealtimeController) Stream(c *gin.Context) {
+	sub, initial, ok := ctrl.subscribe(c)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // tell nginx not to buffer the stream
+	c.Status(http.StatusOK)
+
+	send := func(msg domain.RealtimeMessage) bool {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", msg.Type, data); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+	for _, msg := range initial {
+		if !send(msg) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case msg, open := <-sub.Messages():
+			if !open || !send(msg) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// the same updates over a WebSocket; clients can send {"action": "watch"|"unwatch", "blog_id": "..."}
+func (ctrl *RealtimeController) WebSocket(c *gin.Context) {
+	sub, initial, ok := ctrl.subscribe(c)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	conn, err := ctrl.realtimeUsecase.OpenWebSocket(c.Writer, c.Request)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotWebSocket) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer conn.Close()
+	conn.SetIdleTimeout(webSocketIdleTimeout)
+
+	send := func(msg domain.RealtimeMessage) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return nil
+		}
+		return conn.WriteText(data)
+	}
+	for _, msg := range initial {
+		if send(msg) != nil {
+			return
+		}
+	}
+
+	// commands are read on their own goroutine; it ends when the client goes away
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				Action string `json:"action"`
+				BlogID string `json:"blog_id"`
+			}
+			if err := json.Unmarshal(data, &cmd); err != nil {
+				send(domain.RealtimeMessage{Type: "error", Data: "invalid message"})
+				continue
+			}
+
+			switch cmd.Action {
+			case "watch":
+				msg, err := ctrl.realtimeUsecase.Watch(sub, cmd.BlogID)
+				if err != nil {
+					send(domain.RealtimeMessage{Type: "error", Data: err.Error()})
+					continue
+				}
+				send(msg)
+			case "unwatch":
+				if err := ctrl.realtimeUsecase.Unwatch(sub, cmd.BlogID); err != nil {
+					send(domain.RealtimeMessage{Type: "error", Data: err.Error()})
+				}
+			default:
+				send(domain.RealtimeMessage{Type: "error", Data: "action must be watch or unwatch"})
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamHeartbeat)
+	defer ping.Stop()
+	for {
+		select {
+		case msg, open := <-sub.Messages():
+			if !open || send(msg) != nil {
+				return
+			}
+		case <-ping.C:
+			if conn.Ping() != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"Blog/usecases"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RelatedController struct {
+	relatedUsecase *usecases.RelatedUsecase
+}
+
+func NewRelatedController(u *usecases.RelatedUsecase) *RelatedController {
+	return &RelatedController{relatedUsecase: u}
+}
+
+// list posts related to a blog (?limit=, default 5, max 20)
+func (ctrl *RelatedController) GetRelated(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+			return
+		}
+		limit = n
+	}
+
+	if !primitive.IsValidObjectID(c.Param("id")) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid blog id"})
+		return
+	}
+
+	related, err := ctrl.relatedUsecase.GetRelated(c.Param("id"), limit)
+	if errors.Is(err, domain.ErrBlogNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, related)
+}
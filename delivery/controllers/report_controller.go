@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController struct {
+	reportUsecase *usecases.ReportUsecase
+}
+
+func NewReportController(u *usecases.ReportUsecase) *ReportController {
+	return &ReportController{reportUsecase: u}
+}
+
+// report a blog as abusive
+func (ctrl *ReportController) ReportBlog(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason  string `json:"reason" binding:"required"`
+		Details string `json:"details"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := ctrl.reportUsecase.ReportBlog(userID, c.Param("id"), input.Reason, input.Details)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "report submitted", "report": report})
+}
+
+// list reports for triage (?status=open|resolved|dismissed|all)
+func (ctrl *ReportController) GetReports(c *gin.Context) {
+	reports, err := ctrl.reportUsecase.GetReports(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// uphold a report and take the post down
+func (ctrl *ReportController) ResolveReport(c *gin.Context) {
+	ctrl.triage(c, ctrl.reportUsecase.ResolveReport, "report resolved")
+}
+
+// reject a report
+func (ctrl *ReportController) DismissReport(c *gin.Context) {
+	ctrl.triage(c, ctrl.reportUsecase.DismissReport, "report dismissed")
+}
+
+// helper shared by the resolve and dismiss actions
+func (ctrl *ReportController) triage(c *gin.Context, action func(adminID, reportID, note string) error, message string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Note string `json:"note"`
+	}
+	// the note is optional, so an empty body is fine
+	_ = c.ShouldBindJSON(&input)
+
+	if err := action(userID, c.Param("id"), input.Note); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"Blog/usecases"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SemanticController struct {
+	semanticUsecase *usecases.SemanticUsecase
+}
+
+func NewSemanticController(u *usecases.SemanticUsecase) *SemanticController {
+	return &SemanticController{semanticUsecase: u}
+}
+
+// search blogs by meaning (?q=&limit=, or a JSON body with query and limit)
+func (ctrl *SemanticController) SemanticSearch(c *gin.Context) {
+	query := c.Query("q")
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	if c.Request.Method == http.MethodPost {
+		var body struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, limit = body.Query, body.Limit
+	}
+
+	hits, err := ctrl.semanticUsecase.Search(query, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrEmptySearchQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, hits)
+}
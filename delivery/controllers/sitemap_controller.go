@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"Blog/usecases"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SitemapController struct {
+	sitemapUsecase *usecases.SitemapUsecase
+}
+
+func NewSitemapController(u *usecases.SitemapUsecase) *SitemapController {
+	return &SitemapController{sitemapUsecase: u}
+}
+
+// the sitemap, or the sitemap index once there are too many URLs for one file
+func (ctrl *SitemapController) Sitemap(c *gin.Context) {
+	ctrl.serve(c, "sitemap.xml")
+}
+
+// one of the files listed by the sitemap index
+func (ctrl *SitemapController) SitemapFile(c *gin.Context) {
+	ctrl.serve(c, c.Param("name"))
+}
+
+func (ctrl *SitemapController) Robots(c *gin.Context) {
+	c.String(http.StatusOK, ctrl.sitemapUsecase.Robots())
+}
+
+func (ctrl *SitemapController) serve(c *gin.Context, name string) {
+	doc, err := ctrl.sitemapUsecase.Document(name)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrSitemapNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	writeCacheable(c, "application/xml; charset=utf-8", doc.Body, doc.Modified)
+}
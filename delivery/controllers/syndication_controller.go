@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"Blog/domain"
+	"Blog/usecases"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SyndicationController struct {
+	syndicationUsecase *usecases.SyndicationUsecase
+}
+
+func NewSyndicationController(u *usecases.SyndicationUsecase) *SyndicationController {
+	return &SyndicationController{syndicationUsecase: u}
+}
+
+// RSS 2.0 feed of the newest posts; /authors/:id/ and /tags/:tag/ narrow it down
+func (ctrl *SyndicationController) RSS(c *gin.Context) {
+	ctrl.serve(c, domain.FeedFormatRSS)
+}
+
+// Atom feed of the newest posts
+func (ctrl *SyndicationController) Atom(c *gin.Context) {
+	ctrl.serve(c, domain.FeedFormatAtom)
+}
+
+// JSON Feed of the newest posts
+func (ctrl *SyndicationController) JSONFeed(c *gin.Context) {
+	ctrl.serve(c, domain.FeedFormatJSON)
+}
+
+// helper to build and write a feed; ?mode=excerpt shortens every post
+func (ctrl *SyndicationController) serve(c *gin.Context, format string) {
+	feed, err := ctrl.syndicationUsecase.GetFeed(c.Param("id"), c.Param("tag"), c.Query("mode"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	body, contentType, err := ctrl.syndicationUsecase.RenderFeed(feed, format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	writeCacheable(c, contentType, body, feed.Updated)
+}
@@ -85,8 +85,17 @@ func (ctrl *UserController) PromoteUser(ctx *gin.Context) {
 }
 
 func (ctrl *UserController) GetUsers(ctx *gin.Context) {
-	users, err := ctrl.userUsecase.GetAllUsers()
+	page, ok := pageRequest(ctx)
+	if !ok {
+		return
+	}
+
+	users, err := ctrl.userUsecase.GetAllUsers(page)
 	if err != nil {
+		if listErrorStatus(err) == http.StatusBadRequest {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch users"})
 		return
 	}
@@ -8,6 +8,12 @@ import (
 	"Blog/repositories"
 	"Blog/usecases"
 	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -26,25 +32,283 @@ func main() {
 	// initialize collections
 	blogCollection := client.Database("Blog").Collection("blogs")
 	userCollection := client.Database("User").Collection("users")
+	commentCollection := client.Database("Blog").Collection("comments")
+	reportCollection := client.Database("Blog").Collection("reports")
+	embeddingCollection := client.Database("Blog").Collection("blog_embeddings")
+	statsCollection := client.Database("Blog").Collection("blog_stats")
+	bookmarkCollection := client.Database("Blog").Collection("bookmarks")
+	followCollection := client.Database("Blog").Collection("follows")
+	notificationCollection := client.Database("Blog").Collection("notifications")
+	newsletterCollection := client.Database("Blog").Collection("newsletter_subscriptions")
+	mediaCollection := client.Database("Blog").Collection("media")
 
 	// initialize repositories
-	var blogRepo domain.BlogRepository = repositories.NewMongoBlogRepository(*blogCollection)
+	mongoBlogRepo := repositories.NewMongoBlogRepository(*blogCollection)
+	var blogRepo domain.BlogRepository = mongoBlogRepo
 	var userRepo domain.UserRepository = repositories.NewMongoUserRepository(userCollection)
+	var commentRepo domain.CommentRepository = repositories.NewMongoCommentRepository(commentCollection)
+	var reportRepo domain.ReportRepository = repositories.NewMongoReportRepository(reportCollection)
+	var embeddingRepo domain.EmbeddingRepository = repositories.NewMongoEmbeddingRepository(embeddingCollection)
+	var analyticsRepo domain.AnalyticsRepository = repositories.NewMongoAnalyticsRepository(statsCollection)
+	var bookmarkRepo domain.BookmarkRepository = repositories.NewMongoBookmarkRepository(bookmarkCollection)
+	var followRepo domain.FollowRepository = repositories.NewMongoFollowRepository(followCollection)
+	var notificationRepo domain.NotificationRepository = repositories.NewMongoNotificationRepository(notificationCollection)
+	var mediaRepo domain.MediaRepository = repositories.NewMongoMediaRepository(mediaCollection)
+	var newsletterRepo domain.NewsletterRepository = repositories.NewMongoNewsletterRepository(newsletterCollection)
+	var dashboardRepo domain.DashboardRepository = repositories.NewMongoDashboardRepository(client.Database("Blog"))
+	var adminStatsRepo domain.AdminStatsRepository = repositories.NewMongoAdminStatsRepository(userCollection, client.Database("Blog"))
 
 	// initialize AI service
-	var aiService domain.AIService = infrastructure.NewMistralAIService()
+	mistral := infrastructure.NewMistralAIService()
+	var aiService domain.AIService = mistral
+
+	// initialize embedder: Mistral when an API key is configured, otherwise the local hashing embedder
+	var embedder domain.Embedder = infrastructure.NewHashEmbedder(256)
+	if os.Getenv("M_API_KEY") != "" && os.Getenv("EMBEDDER") != "hash" {
+		embedder = mistral
+	}
+
+	// initialize event bus
+	var events domain.EventBus = infrastructure.NewInMemoryEventBus()
+
+	// initialize the mailer: SMTP when SMTP_ADDR is set, otherwise emails are written to MAIL_DIR
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "no-reply@localhost"
+	}
+	var mailer domain.Mailer
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		mailer = infrastructure.NewSMTPMailer(addr, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), mailFrom)
+	} else {
+		mailDir := os.Getenv("MAIL_DIR")
+		if mailDir == "" {
+			mailDir = "mail"
+		}
+		fileMailer, err := infrastructure.NewFileMailer(mailDir, mailFrom)
+		if err != nil {
+			log.Fatal("could not create the mail directory:", err)
+		}
+		mailer = fileMailer
+	}
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:3000"
+	}
+	// the frontend serving post, author and tag pages; feeds and sitemaps point readers there
+	siteURL := os.Getenv("SITE_URL")
+
+	// initialize media storage: an S3-compatible bucket with MEDIA_STORAGE=s3, otherwise MEDIA_DIR on local disk
+	var mediaStorage domain.MediaStorage
+	if os.Getenv("MEDIA_STORAGE") == "s3" {
+		mediaStorage = infrastructure.NewS3MediaStorage(infrastructure.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	} else {
+		mediaDir := os.Getenv("MEDIA_DIR")
+		if mediaDir == "" {
+			mediaDir = "uploads"
+		}
+		localStorage, err := infrastructure.NewLocalMediaStorage(mediaDir, baseURL+"/uploads")
+		if err != nil {
+			log.Fatal("could not create the media directory:", err)
+		}
+		mediaStorage = localStorage
+	}
+
+	// initialize the pub/sub hub behind live updates
+	var hub domain.PubSub = infrastructure.NewInMemoryPubSub()
+
+	// initialize GeoIP lookups for analytics from a local "start_ip,end_ip,country" CSV file
+	var geo domain.GeoLocator
+	if path := os.Getenv("GEOIP_DB"); path != "" {
+		csvGeo, err := infrastructure.NewCSVGeoIP(path)
+		if err != nil {
+			log.Println("Warning: could not load GeoIP database:", err)
+		} else {
+			geo = csvGeo
+		}
+	}
+
+	// initialize the reaction set, e.g. REACTION_TYPES="like:👍,dislike:👎,love:❤️"
+	reactionTypes, err := usecases.ParseReactionTypes(os.Getenv("REACTION_TYPES"))
+	if err != nil {
+		log.Println("Warning: invalid REACTION_TYPES, using the default reactions:", err)
+		reactionTypes = domain.DefaultReactionTypes
+	}
+
+	// initialize search engine: SEARCH_ENGINE=memory uses the in-process index instead of Mongo text search
+	var searchEngine domain.SearchEngine = repositories.NewMongoSearchEngine(mongoBlogRepo)
+	if os.Getenv("SEARCH_ENGINE") == "memory" {
+		searchEngine = infrastructure.NewInMemorySearchEngine()
+		usecases.SyncSearchIndex(events, searchEngine)
+	}
 
 	// initialize usecases
-	blogUsecase := usecases.NewBlogUsecase(blogRepo, aiService)
-	userUsecase := usecases.NewUserUsecase(userRepo)
+	viewTracker := usecases.NewViewTracker(blogRepo, time.Duration(envInt("VIEW_DEDUP_MINUTES", 30))*time.Minute)
+	blogUsecase := usecases.NewBlogUsecase(blogRepo, userRepo, aiService, searchEngine, events, viewTracker, mediaRepo)
+	userUsecase := usecases.NewUserUsecase(userRepo, events)
+	commentUsecase := usecases.NewCommentUsecase(commentRepo, blogRepo, userRepo, events)
+	notificationUsecase := usecases.NewNotificationUsecase(notificationRepo, blogRepo, commentRepo, userRepo, events)
+	realtimeUsecase := usecases.NewRealtimeUsecase(hub, blogRepo, infrastructure.NewWebSocketUpgrader(), events)
+	siteTitle := os.Getenv("SITE_TITLE")
+	if siteTitle == "" {
+		siteTitle = "Blog"
+	}
+	syndicationUsecase := usecases.NewSyndicationUsecase(blogRepo, userRepo, infrastructure.NewFeedRenderer(), siteTitle, baseURL, siteURL, events)
+	// robots.txt rules may be replaced by the contents of ROBOTS_FILE
+	robots := ""
+	if path := os.Getenv("ROBOTS_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Println("Warning: could not read ROBOTS_FILE, serving the default robots.txt:", err)
+		}
+		robots = string(content)
+	}
+	mediaUsecase := usecases.NewMediaUsecase(mediaRepo, blogRepo, mediaStorage, infrastructure.NewStdImageProcessor(), int64(envInt("MEDIA_MAX_UPLOAD_MB", 10))<<20, int64(envInt("MEDIA_QUOTA_MB", 500))<<20)
+	sitemapUsecase := usecases.NewSitemapUsecase(blogRepo, baseURL, siteURL, robots, events)
+	newsletterUsecase := usecases.NewNewsletterUsecase(newsletterRepo, blogRepo, userRepo, mailer, baseURL, siteURL, time.Duration(envInt("DIGEST_INTERVAL_DAYS", 7))*24*time.Hour)
+	trendingUsecase := usecases.NewTrendingUsecase(blogRepo, commentRepo)
+	reportUsecase := usecases.NewReportUsecase(reportRepo, blogRepo, notificationUsecase, events, envInt("REPORT_HIDE_THRESHOLD", 5))
+	relatedUsecase := usecases.NewRelatedUsecase(blogRepo, searchEngine, events)
+	semanticUsecase := usecases.NewSemanticUsecase(blogRepo, embeddingRepo, embedder, events)
+	analyticsUsecase := usecases.NewAnalyticsUsecase(analyticsRepo, blogRepo, userRepo, geo, events)
+	dashboardUsecase := usecases.NewDashboardUsecase(dashboardRepo)
+	adminStatsUsecase := usecases.NewAdminStatsUsecase(adminStatsRepo, events)
+	reactionUsecase := usecases.NewReactionUsecase(blogRepo, reactionTypes, events)
+	bookmarkUsecase := usecases.NewBookmarkUsecase(bookmarkRepo, blogRepo, events)
+	feedUsecase := usecases.NewFeedUsecase(followRepo, blogRepo, userRepo, events, envInt("FEED_POPULAR_FOLLOWERS", 1000))
+
+	// prepare full-text search
+	if err := blogRepo.EnsureSearchIndex(); err != nil {
+		log.Println("Warning: could not create blog search index:", err)
+	}
+	if err := analyticsRepo.EnsureIndexes(); err != nil {
+		log.Println("Warning: could not create analytics indexes:", err)
+	}
+	if err := mediaRepo.EnsureIndexes(); err != nil {
+		log.Println("Warning: could not create media indexes:", err)
+	}
+	if err := bookmarkRepo.EnsureIndexes(); err != nil {
+		log.Println("Warning: could not create bookmark indexes:", err)
+	}
+	if err := followRepo.EnsureIndexes(); err != nil {
+		log.Println("Warning: could not create follow indexes:", err)
+	}
+	if err := notificationRepo.EnsureIndexes(); err != nil {
+		log.Println("Warning: could not create notification indexes:", err)
+	}
+	if err := newsletterRepo.EnsureIndexes(); err != nil {
+		log.Println("Warning: could not create newsletter indexes:", err)
+	}
+
+	// one reaction per user and blog, and counters that match the interactions (this also
+	// fills the counters of blogs from before reactions existed) before any new reaction lands
+	if err := blogRepo.EnsureInteractionIndex(); err != nil {
+		log.Println("Warning: could not create the reaction index:", err)
+	}
+	if n, err := reactionUsecase.Reconcile(); err != nil {
+		log.Println("Warning: reaction reconciliation failed:", err)
+	} else if n > 0 {
+		log.Printf("reaction counters corrected on %d blogs", n)
+	}
+	go func() {
+		if err := blogUsecase.BackfillAuthorNames(); err != nil {
+			log.Println("Warning: author name backfill failed:", err)
+		}
+		if os.Getenv("SEARCH_ENGINE") == "memory" {
+			if count, err := blogUsecase.RebuildSearchIndex(); err != nil {
+				log.Println("Warning: search index rebuild failed:", err)
+			} else {
+				log.Printf("search index rebuilt with %d blogs", count)
+			}
+		}
+		if count, err := sitemapUsecase.Load(); err != nil {
+			log.Println("Warning: sitemap load failed:", err)
+		} else {
+			log.Printf("sitemap loaded with %d blogs", count)
+		}
+		if count, err := semanticUsecase.Reindex(); err != nil {
+			log.Println("Warning: semantic index rebuild failed:", err)
+		} else {
+			log.Printf("embedded %d new or changed blogs", count)
+		}
+	}()
+
+	// keep reaction counters in line with the interaction log
+	go reactionUsecase.Run(time.Duration(envInt("RECONCILE_INTERVAL_MINUTES", 60))*time.Minute, nil)
+
+	// pick the authors whose timelines are cached for feeds
+	go feedUsecase.Run(time.Duration(envInt("FEED_POPULAR_REFRESH_MINUTES", 10))*time.Minute, nil)
+
+	// send email digests to subscribers whose period is up
+	go newsletterUsecase.Run(time.Duration(envInt("NEWSLETTER_CHECK_MINUTES", 60))*time.Minute, nil)
+
+	// strip metadata from uploaded images and render their variants
+	go mediaUsecase.RunWorkers(envInt("MEDIA_WORKERS", 2), nil)
+
+	// recompute trending scores in the background
+	go trendingUsecase.Run(time.Duration(envInt("TRENDING_INTERVAL_MINUTES", 10))*time.Minute, nil)
+
+	// flush buffered view counts and analytics periodically, and once more on shutdown
+	flushInterval := time.Duration(envInt("VIEW_FLUSH_SECONDS", 30)) * time.Second
+	stopFlush := make(chan struct{})
+	var flushed sync.WaitGroup
+	flushed.Add(2)
+	go func() {
+		defer flushed.Done()
+		viewTracker.Run(flushInterval, stopFlush)
+	}()
+	go func() {
+		defer flushed.Done()
+		analyticsUsecase.Run(flushInterval, stopFlush)
+	}()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		close(stopFlush)
+		flushed.Wait()
+		os.Exit(0)
+	}()
 
 	// initialize controllers
-	blogController := controllers.NewTaskController(blogUsecase)
+	blogController := controllers.NewTaskController(blogUsecase, bookmarkUsecase, mediaUsecase)
 	userController := controllers.NewUserController(userUsecase)
+	commentController := controllers.NewCommentController(commentUsecase)
+	reportController := controllers.NewReportController(reportUsecase)
+	relatedController := controllers.NewRelatedController(relatedUsecase)
+	semanticController := controllers.NewSemanticController(semanticUsecase)
+	analyticsController := controllers.NewAnalyticsController(analyticsUsecase)
+	dashboardController := controllers.NewDashboardController(dashboardUsecase)
+	adminStatsController := controllers.NewAdminStatsController(adminStatsUsecase)
+	reactionController := controllers.NewReactionController(reactionUsecase)
+	bookmarkController := controllers.NewBookmarkController(bookmarkUsecase)
+	feedController := controllers.NewFeedController(feedUsecase, bookmarkUsecase)
+	notificationController := controllers.NewNotificationController(notificationUsecase)
+	realtimeController := controllers.NewRealtimeController(realtimeUsecase)
+	newsletterController := controllers.NewNewsletterController(newsletterUsecase)
+	syndicationController := controllers.NewSyndicationController(syndicationUsecase)
+	sitemapController := controllers.NewSitemapController(sitemapUsecase)
+	mediaController := controllers.NewMediaController(mediaUsecase)
 
 	// setup router
-	r := routes.SetUpRouter(blogController, userController, userUsecase)
+	r := routes.SetUpRouter(blogController, userController, commentController, reportController, relatedController, semanticController, analyticsController, dashboardController, adminStatsController, reactionController, bookmarkController, feedController, notificationController, realtimeController, newsletterController, syndicationController, sitemapController, mediaController, userUsecase)
 
 	// start server
 	r.Run(":3000")
 }
+
+// envInt reads an integer setting from the environment, falling back to def
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -4,11 +4,12 @@ import (
 	"Blog/delivery/controllers"
 	"Blog/infrastructure"
 	"Blog/usecases"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetUpRouter(blogCtrl *controllers.BlogController, userCtrl *controllers.UserController, useCase *usecases.UserUsecase) (*gin.Engine) {
+func SetUpRouter(blogCtrl *controllers.BlogController, userCtrl *controllers.UserController, commentCtrl *controllers.CommentController, reportCtrl *controllers.ReportController, relatedCtrl *controllers.RelatedController, semanticCtrl *controllers.SemanticController, analyticsCtrl *controllers.AnalyticsController, dashboardCtrl *controllers.DashboardController, adminStatsCtrl *controllers.AdminStatsController, reactionCtrl *controllers.ReactionController, bookmarkCtrl *controllers.BookmarkController, feedCtrl *controllers.FeedController, notificationCtrl *controllers.NotificationController, realtimeCtrl *controllers.RealtimeController, newsletterCtrl *controllers.NewsletterController, syndicationCtrl *controllers.SyndicationController, sitemapCtrl *controllers.SitemapController, mediaCtrl *controllers.MediaController, useCase *usecases.UserUsecase) (*gin.Engine) {
 	r := gin.Default()
 
 	// register and login (public routes)
@@ -26,19 +27,114 @@ func SetUpRouter(blogCtrl *controllers.BlogController, userCtrl *controllers.Use
 	adminRouter.GET("/users", userCtrl.GetUsers)
 	adminRouter.POST("/users/promote", userCtrl.PromoteUser)
 	adminRouter.DELETE("/users/:id", userCtrl.DeleteUser)
+	adminRouter.GET("/comments/blocked-words", infrastructure.AdminOnly(useCase), commentCtrl.GetBlockedWords)
+	adminRouter.PUT("/comments/blocked-words", infrastructure.AdminOnly(useCase), commentCtrl.SetBlockedWords)
+	adminRouter.GET("/reports", infrastructure.AdminOnly(useCase), reportCtrl.GetReports)
+	adminRouter.POST("/reports/:id/resolve", infrastructure.AdminOnly(useCase), reportCtrl.ResolveReport)
+	adminRouter.POST("/reports/:id/dismiss", infrastructure.AdminOnly(useCase), reportCtrl.DismissReport)
+	adminRouter.GET("/stats", infrastructure.AdminOnly(useCase), adminStatsCtrl.GetStats)
 
 
 	blogRouter := r.Group("/blog")
 	blogRouter.POST("/", infrastructure.AuthMiddleware(), blogCtrl.CreateBlog)
 	blogRouter.PUT("/:id", infrastructure.AuthMiddleware(), blogCtrl.UpdateBlog)
-	blogRouter.GET("/:id", blogCtrl.GetBlog)
-	blogRouter.GET("/", blogCtrl.GetBlogs)
-	blogRouter.POST("/blogs/search", blogCtrl.SearchBlog)
+	blogRouter.GET("/:id", infrastructure.OptionalAuth(), blogCtrl.GetBlog)
+	blogRouter.GET("/", infrastructure.OptionalAuth(), blogCtrl.GetBlogs)
+	blogRouter.GET("/blogs/search", infrastructure.OptionalAuth(), blogCtrl.SearchBlog)
+	blogRouter.POST("/blogs/search", infrastructure.OptionalAuth(), blogCtrl.SearchBlog)
+	blogRouter.GET("/blogs/autocomplete", blogCtrl.SuggestSearch)
+	// every semantic query is a call to the embedding provider, so GET and POST share one budget per user or IP
+	semanticLimit := infrastructure.RateLimit(60, time.Hour)
+	blogRouter.GET("/blogs/semantic-search", infrastructure.OptionalAuth(), semanticLimit, semanticCtrl.SemanticSearch)
+	blogRouter.POST("/blogs/semantic-search", infrastructure.OptionalAuth(), semanticLimit, semanticCtrl.SemanticSearch)
 	blogRouter.DELETE("/:id", infrastructure.AuthMiddleware(), blogCtrl.DeleteBlog)
 	blogRouter.POST("/:id/like",infrastructure.AuthMiddleware(), blogCtrl.LikeBlog)
 	blogRouter.POST("/:id/dislike", infrastructure.AuthMiddleware(),blogCtrl.DislikeBlog)
-	blogRouter.POST("/filter", blogCtrl.FilterBlogs)
+	blogRouter.DELETE("/:id/like", infrastructure.AuthMiddleware(), blogCtrl.UnlikeBlog)
+	blogRouter.DELETE("/:id/dislike", infrastructure.AuthMiddleware(), blogCtrl.UndislikeBlog)
+	blogRouter.GET("/reactions", reactionCtrl.GetReactionTypes)
+	blogRouter.PUT("/:id/reactions/:type", infrastructure.AuthMiddleware(), reactionCtrl.SetReaction)
+	blogRouter.DELETE("/:id/reactions/:type", infrastructure.AuthMiddleware(), reactionCtrl.RemoveReaction)
+	blogRouter.GET("/trending", infrastructure.OptionalAuth(), blogCtrl.GetTrending)
+	blogRouter.PUT("/:id/bookmark", infrastructure.AuthMiddleware(), bookmarkCtrl.AddBookmark)
+	blogRouter.DELETE("/:id/bookmark", infrastructure.AuthMiddleware(), bookmarkCtrl.RemoveBookmark)
+	blogRouter.GET("/:id/related", relatedCtrl.GetRelated)
+	blogRouter.GET("/:id/stats", infrastructure.AuthMiddleware(), analyticsCtrl.GetBlogStats)
+	blogRouter.GET("/filter", infrastructure.OptionalAuth(), blogCtrl.FilterBlogs)
+	blogRouter.POST("/filter", infrastructure.OptionalAuth(), blogCtrl.FilterBlogs)
 	blogRouter.POST("/suggest", infrastructure.AuthMiddleware(), blogCtrl.SuggestBlog)
+	blogRouter.GET("/:id/comments", commentCtrl.GetComments)
+	blogRouter.POST("/:id/comments", infrastructure.AuthMiddleware(), commentCtrl.AddComment)
+	blogRouter.PUT("/:id/comment-settings", infrastructure.AuthMiddleware(), commentCtrl.SetCommentMode)
+	blogRouter.POST("/:id/report", infrastructure.AuthMiddleware(), infrastructure.RateLimit(5, time.Hour), reportCtrl.ReportBlog)
+
+	// comment moderation (blog authors see their own posts, admins see everything)
+	commentRouter := r.Group("/comments")
+	commentRouter.Use(infrastructure.AuthMiddleware())
+	commentRouter.GET("/moderation", commentCtrl.GetModerationQueue)
+	commentRouter.POST("/:id/moderate", commentCtrl.ModerateComment)
+
+	// the signed-in user's own data
+	meRouter := r.Group("/me")
+	meRouter.Use(infrastructure.AuthMiddleware())
+	meRouter.GET("/dashboard", dashboardCtrl.GetDashboard)
+	meRouter.GET("/feed", feedCtrl.GetFeed)
+	meRouter.GET("/following", feedCtrl.GetFollowing)
+	meRouter.PUT("/following/authors/:id", feedCtrl.FollowAuthor)
+	meRouter.DELETE("/following/authors/:id", feedCtrl.UnfollowAuthor)
+	meRouter.PUT("/following/tags/:tag", feedCtrl.FollowTag)
+	meRouter.DELETE("/following/tags/:tag", feedCtrl.UnfollowTag)
+	meRouter.GET("/notifications", notificationCtrl.GetNotifications)
+	meRouter.POST("/notifications/read-all", notificationCtrl.MarkAllRead)
+	meRouter.POST("/notifications/:id/read", notificationCtrl.MarkRead)
+	meRouter.GET("/notification-preferences", notificationCtrl.GetPreferences)
+	meRouter.PUT("/notification-preferences", notificationCtrl.SetPreferences)
+	meRouter.GET("/media", mediaCtrl.GetLibrary)
+	meRouter.GET("/bookmarks", bookmarkCtrl.GetBookmarks)
+	meRouter.GET("/lists", bookmarkCtrl.GetMyLists)
+	meRouter.POST("/lists", bookmarkCtrl.CreateList)
+	meRouter.PATCH("/lists/:id", bookmarkCtrl.UpdateList)
+	meRouter.DELETE("/lists/:id", bookmarkCtrl.DeleteList)
+	meRouter.POST("/lists/:id/items", bookmarkCtrl.AddToList)
+	meRouter.PUT("/lists/:id/items", bookmarkCtrl.ReorderList)
+	meRouter.DELETE("/lists/:id/items/:blogId", bookmarkCtrl.RemoveFromList)
+
+	// live updates; the token may be sent as ?access_token= since browsers cannot set headers here
+	r.GET("/events", infrastructure.StreamAuth(), realtimeCtrl.Stream)
+	r.GET("/ws", infrastructure.StreamAuth(), realtimeCtrl.WebSocket)
+
+	// email digests with double opt-in
+	newsletterRouter := r.Group("/newsletter")
+	newsletterRouter.POST("/subscriptions", infrastructure.RateLimit(10, time.Hour), newsletterCtrl.Subscribe)
+	newsletterRouter.GET("/confirm", newsletterCtrl.Confirm)
+	newsletterRouter.GET("/unsubscribe", newsletterCtrl.UnsubscribeForm)
+	newsletterRouter.POST("/unsubscribe", newsletterCtrl.Unsubscribe)
+
+	// RSS, Atom and JSON feeds of the whole blog, one author or one tag (?mode=excerpt for summaries)
+	r.GET("/feed.rss", syndicationCtrl.RSS)
+	r.GET("/feed.atom", syndicationCtrl.Atom)
+	r.GET("/feed.json", syndicationCtrl.JSONFeed)
+	r.GET("/authors/:id/feed.rss", syndicationCtrl.RSS)
+	r.GET("/authors/:id/feed.atom", syndicationCtrl.Atom)
+	r.GET("/authors/:id/feed.json", syndicationCtrl.JSONFeed)
+	r.GET("/tags/:tag/feed.rss", syndicationCtrl.RSS)
+	r.GET("/tags/:tag/feed.atom", syndicationCtrl.Atom)
+	r.GET("/tags/:tag/feed.json", syndicationCtrl.JSONFeed)
+
+	// media libraries; files on local storage are served from /uploads
+	mediaRouter := r.Group("/media")
+	mediaRouter.POST("/", infrastructure.AuthMiddleware(), infrastructure.RateLimit(100, time.Hour), mediaCtrl.Upload)
+	mediaRouter.GET("/:id", mediaCtrl.GetMedia)
+	mediaRouter.DELETE("/:id", infrastructure.AuthMiddleware(), mediaCtrl.DeleteMedia)
+	r.GET("/uploads/*key", mediaCtrl.ServeFile)
+
+	// crawler support; /sitemaps/ files only exist once the sitemap is split into an index
+	r.GET("/sitemap.xml", sitemapCtrl.Sitemap)
+	r.GET("/sitemaps/:name", sitemapCtrl.SitemapFile)
+	r.GET("/robots.txt", sitemapCtrl.Robots)
+
+	// reading lists are shared by link; private ones are visible to their owner only
+	r.GET("/lists/:id", infrastructure.OptionalAuth(), bookmarkCtrl.GetList)
 
 	return r
 }
\ No newline at end of file
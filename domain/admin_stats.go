@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminStats is the site-wide activity report for the admin statistics endpoint
+type AdminStats struct {
+	Since       time.Time `json:"since"`
+	Days        int       `json:"days"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Users struct {
+		Total   int          `json:"total"`
+		Signups []DailyCount `json:"signups"`
+	} `json:"users"`
+
+	Authors struct {
+		Total  int `json:"total"`  // users who have ever published
+		Active int `json:"active"` // users who published within the range
+	} `json:"authors"`
+
+	Posts struct {
+		Total  int          `json:"total"`
+		PerDay []DailyCount `json:"per_day"`
+	} `json:"posts"`
+
+	TopTags []FacetCount `json:"top_tags"`
+
+	Interactions struct {
+		Totals StatCounts     `json:"totals"`
+		PerDay []*StatsBucket `json:"per_day"`
+	} `json:"interactions"`
+
+	AISuggestions struct {
+		Total  int          `json:"total"`
+		Failed int          `json:"failed"`
+		PerDay []DailyCount `json:"per_day"`
+	} `json:"ai_suggestions"`
+}
+
+// AdminStatsRepository runs the site-wide aggregations; every "since" bounds the range
+type AdminStatsRepository interface {
+	CountSignups(since time.Time) (total int, daily []DailyCount, err error)
+	CountAuthors(since time.Time) (total, active int, err error)
+	CountPosts(since time.Time) (total int, daily []DailyCount, err error)
+	TopTags(since time.Time, limit int) ([]FacetCount, error)
+	// InteractionVolume returns one bucket per day that saw any activity
+	InteractionVolume(since time.Time) ([]*StatsBucket, error)
+
+	RecordAISuggestion(userID primitive.ObjectID, failed bool, at time.Time) error
+	AISuggestionUsage(since time.Time) (total, failed int, daily []DailyCount, err error)
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Time-series bucket sizes
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+// Breakdown dimensions recorded for views
+const (
+	DimensionReferrer = "referrer"
+	DimensionCountry  = "country"
+)
+
+var ErrInvalidStatsRange = errors.New("invalid stats range")
+
+// StatCounts are the interaction totals for one bucket or a whole range
+type StatCounts struct {
+	Views    int `json:"views" bson:"views"`
+	Likes    int `json:"likes" bson:"likes"`
+	Dislikes int `json:"dislikes" bson:"dislikes"`
+	Comments int `json:"comments" bson:"comments"`
+}
+
+func (c *StatCounts) Add(o StatCounts) {
+	c.Views += o.Views
+	c.Likes += o.Likes
+	c.Dislikes += o.Dislikes
+	c.Comments += o.Comments
+}
+
+// StatsBucket is one hour or day of a blog's interaction history
+type StatsBucket struct {
+	BlogID      primitive.ObjectID `json:"-" bson:"blog_id"`
+	Granularity string             `json:"-" bson:"granularity"`
+	Start       time.Time          `json:"start" bson:"start"`
+	StatCounts  `bson:",inline"`
+}
+
+// BreakdownCount is how many views one referrer or country brought on one day
+type BreakdownCount struct {
+	BlogID    primitive.ObjectID
+	Day       time.Time
+	Dimension string
+	Value     string
+	Count     int
+}
+
+// BlogStats is the analytics report for one blog over a time range
+type BlogStats struct {
+	BlogID      primitive.ObjectID `json:"blog_id"`
+	Granularity string             `json:"granularity"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Totals      StatCounts         `json:"totals"`
+	Series      []*StatsBucket     `json:"series"`
+	Referrers   []FacetCount       `json:"referrers"`
+	Countries   []FacetCount       `json:"countries"`
+}
+
+// AnalyticsRepository stores pre-aggregated interaction counters
+type AnalyticsRepository interface {
+	// AddStats increments bucket counters and breakdown counters, creating them as needed
+	AddStats(buckets []*StatsBucket, breakdowns []*BreakdownCount) error
+	GetBuckets(blogID primitive.ObjectID, granularity string, from, to time.Time) ([]*StatsBucket, error)
+	GetBreakdown(blogID primitive.ObjectID, dimension string, from, to time.Time, limit int) ([]FacetCount, error)
+	EnsureIndexes() error
+}
+
+// GeoLocator maps an IP address to an ISO country code, or "" when unknown
+type GeoLocator interface {
+	Country(ip string) string
+}
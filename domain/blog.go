@@ -1,30 +1,90 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Blog visibility states
+const (
+	BlogStatusPublished = "published"
+	BlogStatusHidden    = "hidden" // taken down after reports, invisible to readers
+)
+
 // Blog represents a blog post in the system
 type Blog struct {
 	ID          primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
 	Title       string             `json:"title" bson:"title"`
 	Content     string             `json:"content" bson:"content"`
 	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	AuthorName  string             `json:"author_username,omitempty" bson:"author_username,omitempty"` // denormalized for search
 	Tags        []string           `json:"tags" bson:"tags"`
 	Likes       int                `json:"likes" bson:"likes"`
 	Dislikes    int                `json:"dislikes" bson:"dislikes"`
+	Reactions   map[string]int     `json:"reactions,omitempty" bson:"reactions,omitempty"` // count per reaction type
 	ViewCount   int                `json:"view_count" bson:"view_count"`
 	DateCreated time.Time          `json:"date_created" bson:"date_created"`
+	DateUpdated time.Time          `json:"date_updated,omitempty" bson:"date_updated,omitempty"` // last edit by the author
+	CommentMode string             `json:"comment_mode" bson:"comment_mode,omitempty"` // open (default), approval or closed
+	Status      string             `json:"status" bson:"status,omitempty"`             // published (default) or hidden
+	Trending    *TrendingScores    `json:"trending,omitempty" bson:"trending,omitempty"` // maintained by the trending job
+	Bookmarked  *bool              `json:"bookmarked,omitempty" bson:"-"`                // set per request for signed-in readers
+	MediaIDs    []primitive.ObjectID `json:"media_ids,omitempty" bson:"media_ids,omitempty"` // files from the author's media library
+	Media       []*Media           `json:"media,omitempty" bson:"-"`                     // resolved MediaIDs, filled for responses
+}
+
+// BlogUpdate is what an author may change on a post; fields left nil stay as they are.
+// Counters, scores, ownership and dates are never taken from the client.
+type BlogUpdate struct {
+	Title    *string               `json:"title"`
+	Content  *string               `json:"content"`
+	Tags     *[]string             `json:"tags"`
+	MediaIDs *[]primitive.ObjectID `json:"media_ids"`
+}
+
+// LastModified is when the post last changed: its last edit, or its creation if never edited
+func (b *Blog) LastModified() time.Time {
+	if b.DateUpdated.After(b.DateCreated) {
+		return b.DateUpdated
+	}
+	return b.DateCreated
+}
+
+// Trending windows
+const (
+	TrendingDay   = "day"
+	TrendingWeek  = "week"
+	TrendingMonth = "month"
+)
+
+// RelatedBlog is a recommended post with why it was picked
+type RelatedBlog struct {
+	Blog    *Blog    `json:"blog"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"` // shared_tags, liked_by_same_readers, similar_text
 }
 
-// BlogInteraction tracks which user liked/disliked a blog to prevent duplicates
+var (
+	ErrBlogNotFound          = errors.New("blog not found")
+	ErrInvalidTrendingWindow = errors.New("window must be day, week or month")
+)
+
+// TrendingScores are time-decayed engagement scores, one per trending window
+type TrendingScores struct {
+	Day       float64   `json:"day" bson:"day"`
+	Week      float64   `json:"week" bson:"week"`
+	Month     float64   `json:"month" bson:"month"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// BlogInteraction records the one reaction a user has left on a blog
 type BlogInteraction struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	BlogID    primitive.ObjectID `bson:"blog_id" json:"blog_id"`
 	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Action    string             `bson:"action" json:"action"` // "like" or "dislike"
+	Action    string             `bson:"action" json:"action"` // the reaction type, e.g. "like" or "dislike"
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 }
 
@@ -32,18 +92,58 @@ type BlogInteraction struct {
 type BlogRepository interface {
 	CreateBlog(blog *Blog) (*Blog, error)
 	GetBlogByID(id primitive.ObjectID) (*Blog, error)
-	GetAllBlogs() ([]*Blog, error)
-	UpdateBlog(id primitive.ObjectID, blog *Blog) (*Blog, error)
+	GetAllBlogs(page PageRequest) (*Page[*Blog], error)
+	UpdateBlog(id primitive.ObjectID, update BlogUpdate) (*Blog, error) // returns the stored post after the update
 	DeleteBlog(id primitive.ObjectID) error
-	SearchBlog(title string) (*Blog, error)
 
 	// Popularity tracking
-	LikeBlog(userID, blogID primitive.ObjectID) error
-	DislikeBlog(userID, blogID primitive.ObjectID) error
-	IncrementViewCount(blogID primitive.ObjectID) error
+	SetReaction(userID, blogID primitive.ObjectID, reaction string) (previous string, err error)
+	RemoveReaction(userID, blogID primitive.ObjectID, reaction string) error
+	EnsureInteractionIndex() error // one reaction per user and blog
+	ReconcileReactions() (int, error) // recomputes counters from interactions
+	AddViewCounts(counts map[primitive.ObjectID]int) error // batched view increments
+
+	// Comment settings
+	SetCommentMode(blogID primitive.ObjectID, mode string) error
+
+	// Moderation
+	SetBlogStatus(blogID primitive.ObjectID, status string) error
+
+	// Full-text search over title, content, tags and author username, ranked by relevance
+	EnsureSearchIndex() error
+	SearchBlogs(terms []string, page PageRequest) (*Page[*SearchHit], error)
+	GetAuthorsMissingName() ([]primitive.ObjectID, error)
+	SetAuthorName(userID primitive.ObjectID, username string) error
+
+	// Trending
+	UpdateTrendingScores(scores map[primitive.ObjectID]TrendingScores) error
+	// ScanRecentBlogs walks the visible posts created since the given time in _id order, with only
+	// the fields trending scores need, starting after afterID
+	ScanRecentBlogs(since time.Time, afterID primitive.ObjectID, limit int) ([]*Blog, error)
+	// ResetTrendingScores zeroes the scores of posts created before the given time that have
+	// aged out of every window, or were never scored, so keyset paging never meets a null score
+	ResetTrendingScores(before time.Time) (int, error)
+	GetTrending(window string, since time.Time, page PageRequest) (*Page[*Blog], error)
+
+	// Recommendations
+	GetBlogsByIDs(ids []primitive.ObjectID) ([]*Blog, error)
+	FindBlogsByTags(tags []string, excludeID primitive.ObjectID, limit int) ([]*Blog, error)
+	GetCoLikedBlogs(blogID primitive.ObjectID, limit int) (map[primitive.ObjectID]int, error)
 
-	// Filtration
-	FilterBlogs(tags []string, startDate, endDate *time.Time, sortBy string) ([]*Blog, error)
+	// Filtration; hidden blogs only match when includeHidden is set
+	FilterBlogs(filter *BlogFilter, sortBy string, page PageRequest, includeHidden bool) (*BlogFilterResult, error)
+	// GetFeed returns up to q.Limit visible posts by q.AuthorIDs or tagged with q.Tags, newest first
+	GetFeed(q FeedQuery) ([]*Blog, error)
+	// GetRecentBlogs returns the newest visible posts, optionally only one author's or tag's
+	// and only those created after since
+	GetRecentBlogs(authorID *primitive.ObjectID, tag string, since time.Time, limit int) ([]*Blog, error)
+	// GetBlogsSince returns the first visible posts created after since, oldest first,
+	// optionally only one author's or tag's
+	GetBlogsSince(authorID *primitive.ObjectID, tag string, since time.Time, limit int) ([]*Blog, error)
+	// ScanBlogs walks the visible posts in _id order without their content, starting after afterID
+	ScanBlogs(afterID primitive.ObjectID, limit int) ([]*Blog, error)
+	// RemoveMedia drops a deleted file from every post referencing it
+	RemoveMedia(mediaID primitive.ObjectID) error
 }
 
 type AIService interface {
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reading list limits
+const (
+	MaxReadingLists     = 100
+	MaxReadingListItems = 500
+)
+
+var (
+	ErrReadingListNotFound = errors.New("reading list not found")
+	ErrInvalidListOrder    = errors.New("the new order must contain exactly the blogs already in the list")
+)
+
+// Bookmark is a post a reader saved for later
+type Bookmark struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	BlogID    primitive.ObjectID `json:"blog_id" bson:"blog_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	Blog      *Blog              `json:"blog,omitempty" bson:"-"`
+}
+
+// ReadingList is a named, ordered collection of posts that can be shared publicly
+type ReadingList struct {
+	ID          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID   `json:"user_id" bson:"user_id"`
+	Name        string               `json:"name" bson:"name"`
+	Description string               `json:"description" bson:"description"`
+	Public      bool                 `json:"public" bson:"public"`
+	BlogIDs     []primitive.ObjectID `json:"blog_ids" bson:"blog_ids"` // in reading order
+	CreatedAt   time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at" bson:"updated_at"`
+	Blogs       []*Blog              `json:"blogs,omitempty" bson:"-"` // resolved posts, filled when viewing a list
+}
+
+// BookmarkRepository stores bookmarks and reading lists
+type BookmarkRepository interface {
+	EnsureIndexes() error
+
+	// Bookmarks; adding one twice keeps the original
+	AddBookmark(userID, blogID primitive.ObjectID) error
+	RemoveBookmark(userID, blogID primitive.ObjectID) error
+	GetBookmarks(userID primitive.ObjectID, page PageRequest) (*Page[*Bookmark], error)
+	// BookmarkedAmong reports which of the blogs the user has bookmarked
+	BookmarkedAmong(userID primitive.ObjectID, blogIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error)
+
+	// Reading lists
+	CreateList(list *ReadingList) (*ReadingList, error)
+	GetList(id primitive.ObjectID) (*ReadingList, error)
+	GetLists(userID primitive.ObjectID, publicOnly bool) ([]*ReadingList, error)
+	CountLists(userID primitive.ObjectID) (int, error)
+	// UpdateList saves a list's name, description and visibility; its blogs are left alone
+	UpdateList(list *ReadingList) error
+	// ReorderList sets the order of a list's blogs, provided it still holds exactly those blogs;
+	// ErrInvalidListOrder when it changed in the meantime
+	ReorderList(listID primitive.ObjectID, blogIDs []primitive.ObjectID) error
+	DeleteList(id primitive.ObjectID) error
+	// AddToList inserts a blog at position (appending when position is negative or past the end); no-op if present
+	AddToList(listID, blogID primitive.ObjectID, position int) error
+	RemoveFromList(listID, blogID primitive.ObjectID) error
+
+	// RemoveBlog drops a deleted post from every bookmark and reading list
+	RemoveBlog(blogID primitive.ObjectID) error
+}
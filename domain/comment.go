@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Comment modes an author can set on a blog post
+const (
+	CommentModeOpen     = "open"     // comments are published immediately
+	CommentModeApproval = "approval" // comments wait in the moderation queue
+	CommentModeClosed   = "closed"   // no new comments accepted
+)
+
+// Comment moderation states
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusSpam     = "spam"
+)
+
+var (
+	ErrForbidden        = errors.New("you are not allowed to perform this action")
+	ErrCommentsClosed   = errors.New("comments are closed for this blog")
+	ErrBlockedWords     = errors.New("comment contains blocked words")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrInvalidModAction = errors.New("invalid moderation action")
+)
+
+// Comment represents a reader comment on a blog post
+type Comment struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	BlogID       primitive.ObjectID  `json:"blog_id" bson:"blog_id"`
+	BlogAuthorID primitive.ObjectID  `json:"blog_author_id" bson:"blog_author_id"` // denormalized for the author's moderation queue
+	UserID       primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	ParentID     *primitive.ObjectID `json:"parent_id,omitempty" bson:"parent_id,omitempty"` // the comment this one replies to
+	Content      string              `json:"content" bson:"content"`
+	Status       string              `json:"status" bson:"status"`
+	CreatedAt    time.Time           `json:"created_at" bson:"created_at"`
+	ModeratedBy  *primitive.ObjectID `json:"moderated_by,omitempty" bson:"moderated_by,omitempty"`
+	ModeratedAt  *time.Time          `json:"moderated_at,omitempty" bson:"moderated_at,omitempty"`
+}
+
+// CommentRepository defines repository operations for comments and comment settings
+type CommentRepository interface {
+	CreateComment(comment *Comment) (*Comment, error)
+	GetCommentByID(id primitive.ObjectID) (*Comment, error)
+	GetCommentsByBlog(blogID primitive.ObjectID, status string) ([]*Comment, error)
+	CountByBlogs(blogIDs []primitive.ObjectID, status string) (map[primitive.ObjectID]int, error)
+	// DeleteByBlog removes every comment on a blog, whatever its status
+	DeleteByBlog(blogID primitive.ObjectID) error
+
+	// Moderation
+	GetModerationQueue(blogAuthorID *primitive.ObjectID) ([]*Comment, error)
+	UpdateCommentStatus(id primitive.ObjectID, status string, moderatorID primitive.ObjectID) error
+
+	// Blocked words applied on submission
+	GetBlockedWords() ([]string, error)
+	SetBlockedWords(words []string) error
+}
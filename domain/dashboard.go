@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostStatusCounts is how many posts an author has in each visibility state
+type PostStatusCounts struct {
+	Total     int `json:"total"`
+	Published int `json:"published"`
+	Hidden    int `json:"hidden"`
+}
+
+// PeriodStats are the interactions an author's posts received since a point in time
+type PeriodStats struct {
+	Period string    `json:"period"` // e.g. "7d"
+	Since  time.Time `json:"since"`
+	StatCounts
+}
+
+// DailyCount is a count for one calendar day (UTC)
+type DailyCount struct {
+	Day   time.Time `json:"day" bson:"_id"`
+	Count int       `json:"count" bson:"count"`
+}
+
+// FollowerStats are an author's follower total and new followers per day
+type FollowerStats struct {
+	Total  int          `json:"total"`
+	Growth []DailyCount `json:"growth"`
+}
+
+// AuthorDashboard summarizes how an author's writing is doing
+type AuthorDashboard struct {
+	Posts          PostStatusCounts `json:"posts"`
+	Totals         StatCounts       `json:"totals"`
+	Periods        []PeriodStats    `json:"periods"`
+	TopPosts       []*Blog          `json:"top_posts"`
+	RecentComments []*Comment       `json:"recent_comments"`
+	Followers      FollowerStats    `json:"followers"`
+}
+
+// DashboardRepository aggregates an author's posts, interactions, comments and followers
+type DashboardRepository interface {
+	CountPostsByStatus(authorID primitive.ObjectID) (PostStatusCounts, error)
+	// GetTotals sums the counters stored on the author's posts and their approved comments
+	GetTotals(authorID primitive.ObjectID) (StatCounts, error)
+	// GetActivity counts views, reactions and comments received since the given time
+	GetActivity(authorID primitive.ObjectID, since time.Time) (StatCounts, error)
+	GetTopPosts(authorID primitive.ObjectID, limit int) ([]*Blog, error)
+	GetRecentComments(authorID primitive.ObjectID, limit int) ([]*Comment, error)
+	GetFollowerStats(authorID primitive.ObjectID, since time.Time) (FollowerStats, error)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Embedder turns text into vectors whose cosine similarity reflects similarity of meaning
+type Embedder interface {
+	// Model names the embedding model; vectors from different models are not comparable
+	Model() string
+	Embed(texts []string) ([][]float32, error)
+}
+
+// BlogEmbedding is the stored vector for one blog post
+type BlogEmbedding struct {
+	BlogID      primitive.ObjectID `json:"blog_id" bson:"_id"`
+	Model       string             `json:"model" bson:"model"`
+	Vector      []float32          `json:"vector" bson:"vector"`
+	ContentHash string             `json:"content_hash" bson:"content_hash"` // detects posts edited since they were embedded
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// EmbeddingRepository persists blog vectors so they survive restarts
+type EmbeddingRepository interface {
+	SaveEmbedding(e *BlogEmbedding) error
+	DeleteEmbedding(blogID primitive.ObjectID) error
+	GetEmbeddings(model string) ([]*BlogEmbedding, error)
+}
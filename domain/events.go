@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Blog lifecycle event types
+const (
+	EventBlogCreated       = "blog.created"
+	EventBlogUpdated       = "blog.updated"
+	EventBlogDeleted       = "blog.deleted"
+	EventBlogStatusChanged = "blog.status_changed"
+)
+
+// Reader interaction event types
+const (
+	EventBlogViewed      = "blog.viewed"  // Payload is the Viewer; only deduplicated views are published
+	EventBlogReacted     = "blog.reacted" // Payload is the ReactionChange
+	EventBlogLiked       = "blog.liked"
+	EventBlogDisliked    = "blog.disliked"
+	EventCommentCreated  = "comment.created"  // Payload is the *Comment
+	EventCommentApproved = "comment.approved" // Payload is the *Comment, published when a held comment is approved
+)
+
+// EventAuthorFollowed is published when a user starts following an author; ActorID is the
+// follower and Payload the author's ObjectID
+const EventAuthorFollowed = "author.followed"
+
+// EventUserRoleChanged is published when an admin changes a user's role; Payload is the RoleChange
+const EventUserRoleChanged = "user.role_changed"
+
+// RoleChange is the payload of EventUserRoleChanged
+type RoleChange struct {
+	UserID primitive.ObjectID
+	Role   string
+}
+
+// EventNotificationCreated is published after a notification is stored; Payload is the *Notification
+const EventNotificationCreated = "notification.created"
+
+// EventAISuggestion is published for every AI content suggestion request; Payload is true when it failed
+const EventAISuggestion = "ai.suggestion"
+
+// Event is something that happened in the domain that other parts of the system react to
+type Event struct {
+	Type       string
+	ActorID    primitive.ObjectID // user who caused the event, if any
+	BlogID     primitive.ObjectID
+	Blog       *Blog // snapshot for blog lifecycle events
+	Payload    any   // event-specific extra data
+	OccurredAt time.Time
+}
+
+// EventBus delivers published events to the handlers subscribed to their type
+type EventBus interface {
+	Publish(e Event)
+	Subscribe(eventType string, handler func(Event))
+}
@@ -0,0 +1,21 @@
+package domain
+
+// FacetCount is one bucket of a facet or breakdown, e.g. a tag, an author or a referrer, with its count
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"` // human-readable name, e.g. the author's username
+	Count int    `json:"count"`
+}
+
+// BlogFacets summarize the whole filtered result set, not just the current page
+type BlogFacets struct {
+	Tags    []FacetCount `json:"tags"`
+	Authors []FacetCount `json:"authors"`
+	Months  []FacetCount `json:"months"` // YYYY-MM of date_created, newest first
+}
+
+// BlogFilterResult is a page of filtered blogs plus facet counts for filter sidebars
+type BlogFilterResult struct {
+	Page[*Blog]
+	Facets BlogFacets `json:"facets"`
+}
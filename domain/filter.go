@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// BlogFilter is one node of a filter tree. Every condition set on a node must hold (AND);
+// And, Or and Not nest further nodes. An empty node matches every blog.
+//
+// JSON example:
+//
+//	{"and": [{"tags": {"any": ["go", "rust"]}}, {"or": [{"min_likes": 10}, {"authors": ["64eabc0c6c7f4a76b6c133aa"]}]}]}
+type BlogFilter struct {
+	And []*BlogFilter `json:"and,omitempty"`
+	Or  []*BlogFilter `json:"or,omitempty"`
+	Not *BlogFilter   `json:"not,omitempty"`
+
+	Tags     *TagCondition        `json:"tags,omitempty"`
+	Authors  []primitive.ObjectID `json:"authors,omitempty"`
+	MinLikes *int                 `json:"min_likes,omitempty"`
+	MinViews *int                 `json:"min_views,omitempty"`
+	Status   string               `json:"status,omitempty"`
+	Text     string               `json:"text,omitempty"`
+	From     *time.Time           `json:"from,omitempty"`
+	To       *time.Time           `json:"to,omitempty"`
+}
+
+// TagCondition matches blogs carrying any, all or none of the listed tags
+type TagCondition struct {
+	Any  []string `json:"any,omitempty"`
+	All  []string `json:"all,omitempty"`
+	None []string `json:"none,omitempty"`
+}
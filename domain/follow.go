@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What a user can follow
+const (
+	FollowAuthor = "author"
+	FollowTag    = "tag"
+)
+
+// MaxFollows caps how many authors and tags one user may follow
+const MaxFollows = 1000
+
+var (
+	ErrCannotFollowSelf = errors.New("you cannot follow yourself")
+	ErrTooManyFollows   = errors.New("follow limit reached")
+)
+
+// Follow is a user subscribing to an author's or a tag's posts
+type Follow struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	FollowerID primitive.ObjectID `json:"follower_id" bson:"follower_id"`
+	Type       string             `json:"type" bson:"type"`                               // author or tag
+	AuthorID   primitive.ObjectID `json:"author_id,omitempty" bson:"author_id,omitempty"` // set for author follows
+	Tag        string             `json:"tag,omitempty" bson:"tag,omitempty"`             // set for tag follows
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// FeedQuery selects visible posts by any of the authors or carrying any of the tags,
+// newest first, strictly older than the (Before, BeforeID) position when Before is set
+type FeedQuery struct {
+	AuthorIDs []primitive.ObjectID
+	Tags      []string
+	Before    time.Time
+	BeforeID  primitive.ObjectID
+	Limit     int
+}
+
+// FollowRepository stores the social graph
+type FollowRepository interface {
+	EnsureIndexes() error
+	// Follow saves a follow; following again keeps the original date
+	Follow(f *Follow) error
+	// Unfollow removes a follow matching the follower, type and author or tag
+	Unfollow(f *Follow) error
+	GetFollowing(followerID primitive.ObjectID) ([]*Follow, error)
+	CountFollowing(followerID primitive.ObjectID) (int, error)
+	CountFollowers(authorID primitive.ObjectID) (int, error)
+	// GetPopularAuthors lists authors with at least minFollowers followers
+	GetPopularAuthors(minFollowers int) ([]primitive.ObjectID, error)
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MediaTypes are the accepted upload types, as sniffed from the content, with the
+// extension files of that type are stored under
+var MediaTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"video/mp4":       ".mp4",
+	"application/pdf": ".pdf",
+}
+
+// MaxBlogMedia is how many files one post may reference
+const MaxBlogMedia = 50
+
+// MaxImagePixels is the largest image accepted, in pixels, so decoding one stays within memory
+const MaxImagePixels = 50_000_000
+
+// Media.Status values; images are published once their metadata is stripped
+const (
+	MediaProcessing = "processing"
+	MediaReady      = "ready"
+	MediaFailed     = "failed"
+)
+
+// image variant names, smallest first
+const (
+	VariantThumbnail = "thumbnail"
+	VariantMedium    = "medium"
+	VariantLarge     = "large"
+)
+
+var (
+	ErrMediaNotFound        = errors.New("media not found")
+	ErrUnsupportedMediaType = errors.New("unsupported file type")
+	ErrMediaTooLarge        = errors.New("file is too large")
+	ErrMediaQuotaExceeded   = errors.New("media storage quota exceeded")
+)
+
+// Media is an uploaded file in its owner's media library
+type Media struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Key         string             `json:"-" bson:"key"`             // where the file is kept in MediaStorage
+	URL         string             `json:"url,omitempty" bson:"-"`   // set once the file is ready
+	Filename    string             `json:"filename" bson:"filename"` // as uploaded
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	Width       int                `json:"width,omitempty" bson:"width,omitempty"` // images only
+	Height      int                `json:"height,omitempty" bson:"height,omitempty"`
+	Status      string             `json:"status" bson:"status,omitempty"`
+	StagingKey  string             `json:"-" bson:"staging_key,omitempty"` // the upload as received, until processed
+	Variants    []*MediaVariant    `json:"variants,omitempty" bson:"variants,omitempty"`
+	Blurhash    string             `json:"blurhash,omitempty" bson:"blurhash,omitempty"` // placeholder shown while loading
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// MediaVariant is a resized copy of an uploaded image
+type MediaVariant struct {
+	Name        string `json:"name" bson:"name"`
+	Key         string `json:"-" bson:"key"`
+	URL         string `json:"url" bson:"-"`
+	ContentType string `json:"content_type" bson:"content_type"`
+	Width       int    `json:"width" bson:"width"`
+	Height      int    `json:"height" bson:"height"`
+	Size        int64  `json:"size" bson:"size"`
+}
+
+// ProcessedImage is what an ImageProcessor makes of an uploaded image
+type ProcessedImage struct {
+	Original []byte // re-encoded without metadata; nil when the upload can be kept as it is
+	Width    int    // after applying the EXIF orientation
+	Height   int
+	Variants []ProcessedVariant
+	Blurhash string
+}
+
+// ProcessedVariant is one encoded variant of a ProcessedImage
+type ProcessedVariant struct {
+	Name        string
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// ImageProcessor strips metadata from uploaded images and renders their variants
+type ImageProcessor interface {
+	Supports(contentType string) bool
+	Process(data []byte, contentType string) (*ProcessedImage, error)
+}
+
+// MediaStorage keeps uploaded files as objects under a key, e.g. on local disk or in an S3 bucket
+type MediaStorage interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	// Open returns ErrMediaNotFound when nothing is stored under key
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	// URL is where clients fetch the object
+	URL(key string) string
+}
+
+// MediaRepository stores the media library entries
+type MediaRepository interface {
+	EnsureIndexes() error
+	CreateMedia(media *Media) error
+	GetMedia(id primitive.ObjectID) (*Media, error)
+	GetMediaByIDs(ids []primitive.ObjectID) ([]*Media, error)
+	// GetUserMedia lists a user's library, newest first
+	GetUserMedia(userID primitive.ObjectID, page PageRequest) (*Page[*Media], error)
+	// UpdateMedia replaces a stored entry; ErrMediaNotFound when it was deleted meanwhile
+	UpdateMedia(media *Media) error
+	// GetPendingMedia lists up to limit uploads still waiting to be processed, oldest first
+	GetPendingMedia(limit int) ([]*Media, error)
+	DeleteMedia(id primitive.ObjectID) error
+	// TotalSize is how many bytes a user's library takes up
+	TotalSize(userID primitive.ObjectID) (int64, error)
+}
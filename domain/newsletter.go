@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What an email subscription covers
+const (
+	NewsletterScopeBlog   = "blog" // every new post
+	NewsletterScopeAuthor = "author"
+	NewsletterScopeTag    = "tag"
+)
+
+// Email subscription states
+const (
+	NewsletterPending      = "pending" // waiting for the subscriber to confirm
+	NewsletterConfirmed    = "confirmed"
+	NewsletterUnsubscribed = "unsubscribed"
+)
+
+var (
+	ErrInvalidNewsletterToken = errors.New("invalid or expired link")
+	ErrInvalidEmail           = errors.New("invalid email address")
+)
+
+// NewsletterSubscription is an email address signed up for digests of new posts
+type NewsletterSubscription struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Email            string             `json:"email" bson:"email"`
+	Scope            string             `json:"scope" bson:"scope"`
+	AuthorID         primitive.ObjectID `json:"author_id,omitempty" bson:"author_id,omitempty"`
+	Tag              string             `json:"tag,omitempty" bson:"tag,omitempty"`
+	Status           string             `json:"status" bson:"status"`
+	ConfirmToken     string             `json:"-" bson:"confirm_token"`
+	UnsubscribeToken string             `json:"-" bson:"unsubscribe_token"`
+	RequestedAt      time.Time          `json:"requested_at" bson:"requested_at"` // when confirmation was last asked for
+	ConfirmedAt      *time.Time         `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty"`
+	LastSentAt       *time.Time         `json:"last_sent_at,omitempty" bson:"last_sent_at,omitempty"` // digests cover posts after this
+}
+
+// Email is an outgoing message
+type Email struct {
+	To      string
+	Subject string
+	Body    string            // plain text
+	Headers map[string]string // extra headers such as List-Unsubscribe
+}
+
+// Mailer delivers email
+type Mailer interface {
+	Send(email Email) error
+}
+
+// NewsletterRepository stores email subscriptions
+type NewsletterRepository interface {
+	EnsureIndexes() error
+	// FindSubscription looks up the subscription of an address to one scope and target
+	FindSubscription(email, scope string, authorID primitive.ObjectID, tag string) (*NewsletterSubscription, error)
+	SaveSubscription(s *NewsletterSubscription) error
+	GetByConfirmToken(token string) (*NewsletterSubscription, error)
+	GetByUnsubscribeToken(token string) (*NewsletterSubscription, error)
+	// GetDueSubscriptions returns up to limit confirmed subscriptions last sent at or before the given time
+	GetDueSubscriptions(sentBefore time.Time, limit int) ([]*NewsletterSubscription, error)
+	MarkSent(id primitive.ObjectID, at time.Time) error
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types
+const (
+	NotificationBlogHidden     = "blog_hidden"
+	NotificationBlogRestored   = "blog_restored"
+	NotificationReportResolved = "report_resolved"
+	NotificationReaction       = "reaction"     // someone liked or reacted to the user's post
+	NotificationComment        = "comment"      // someone commented on the user's post
+	NotificationReply          = "reply"        // someone replied to the user's comment
+	NotificationNewFollower    = "new_follower" // someone followed the user
+	NotificationRoleChanged    = "role_changed" // an admin changed the user's role
+)
+
+// NotificationPreferenceTypes are the notifications a user may switch off; moderation
+// notices about the user's own posts are always delivered
+var NotificationPreferenceTypes = []string{
+	NotificationReaction,
+	NotificationComment,
+	NotificationReply,
+	NotificationNewFollower,
+	NotificationRoleChanged,
+}
+
+var (
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+)
+
+// Notification is a message addressed to a single user
+type Notification struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	Type      string              `json:"type" bson:"type"`
+	Message   string              `json:"message" bson:"message"`
+	ActorID   *primitive.ObjectID `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	BlogID    *primitive.ObjectID `json:"blog_id,omitempty" bson:"blog_id,omitempty"`
+	CommentID *primitive.ObjectID `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	Read      bool                `json:"read" bson:"read"`
+	ReadAt    *time.Time          `json:"read_at,omitempty" bson:"read_at,omitempty"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// NotificationList is a page of notifications plus the user's unread total
+type NotificationList struct {
+	Page[*Notification]
+	Unread int `json:"unread"`
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// NotificationRepository persists notifications and per-user delivery preferences
+type NotificationRepository interface {
+	EnsureIndexes() error
+	CreateNotification(n *Notification) error
+	GetNotifications(userID primitive.ObjectID, unreadOnly bool, page PageRequest) (*Page[*Notification], error)
+	CountUnread(userID primitive.ObjectID) (int, error)
+	MarkRead(userID, id primitive.ObjectID) error
+	MarkAllRead(userID primitive.ObjectID) (int, error)
+
+	// GetPreferences returns the stored switches by type; types never set are absent
+	GetPreferences(userID primitive.ObjectID) (map[string]bool, error)
+	SetPreferences(userID primitive.ObjectID, prefs map[string]bool) error
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "errors"
+
+// Page size limits shared by every list endpoint
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// PageRequest asks for one page of a list; Cursor is the next_cursor of the previous page
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// Normalize applies the default page size and caps it at MaxPageSize
+func (p PageRequest) Normalize() PageRequest {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageSize
+	}
+	if p.Limit > MaxPageSize {
+		p.Limit = MaxPageSize
+	}
+	return p
+}
+
+// Page is one slice of a cursor-paginated list
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "errors"
+
+// Reactions that also drive the likes and dislikes counters
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
+var (
+	ErrUnknownReaction = errors.New("unknown reaction type")
+	ErrNoReaction      = errors.New("you have not reacted to this blog with that reaction")
+)
+
+// ReactionType is one reaction readers can leave on a post
+type ReactionType struct {
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+}
+
+// DefaultReactionTypes is the reaction set used when none is configured
+var DefaultReactionTypes = []ReactionType{
+	{Name: ReactionLike, Emoji: "👍"},
+	{Name: ReactionDislike, Emoji: "👎"},
+	{Name: "love", Emoji: "❤️"},
+	{Name: "celebrate", Emoji: "🎉"},
+	{Name: "thinking", Emoji: "🤔"},
+}
+
+// ReactionChange is the payload of EventBlogReacted; Current is empty when a reaction was removed
+type ReactionChange struct {
+	Previous  string         `json:"previous,omitempty"`
+	Current   string         `json:"current,omitempty"`
+	Reactions map[string]int `json:"reactions"` // counters after the change
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Real-time message types
+const (
+	RealtimeReactions    = "reactions"    // Data is ReactionCounts
+	RealtimeNotification = "notification" // Data is the *Notification
+)
+
+// MaxSubscriptionTopics is how many topics one live connection may listen to
+const MaxSubscriptionTopics = 50
+
+var (
+	ErrTooManyTopics = errors.New("too many subscriptions on one connection")
+	ErrNotWebSocket  = errors.New("not a websocket handshake")
+)
+
+// RealtimeMessage is pushed to every subscriber of its topic
+type RealtimeMessage struct {
+	Topic string `json:"topic,omitempty"`
+	Type  string `json:"type"`
+	Data  any    `json:"data"`
+}
+
+// ReactionCounts is the live counter update sent to readers of a blog
+type ReactionCounts struct {
+	BlogID    primitive.ObjectID `json:"blog_id"`
+	Reactions map[string]int     `json:"reactions"`
+}
+
+// BlogTopic carries live updates about one blog
+func BlogTopic(blogID primitive.ObjectID) string {
+	return "blog:" + blogID.Hex()
+}
+
+// UserTopic carries one user's personal updates
+func UserTopic(userID primitive.ObjectID) string {
+	return "user:" + userID.Hex()
+}
+
+// PubSub fans messages out to subscribers by topic. The in-process hub can be replaced
+// by a message broker when the API runs on more than one instance.
+type PubSub interface {
+	Publish(topic string, msg RealtimeMessage)
+	Subscribe(topics ...string) (Subscription, error)
+}
+
+// Subscription is one listener's view of the hub; messages for slow listeners may be dropped
+type Subscription interface {
+	Messages() <-chan RealtimeMessage
+	Add(topic string) error
+	Remove(topic string)
+	Close()
+}
+
+// WebSocketUpgrader takes over HTTP connections that ask to become WebSockets
+type WebSocketUpgrader interface {
+	// Upgrade completes the opening handshake; ErrNotWebSocket when the request is not one
+	Upgrade(w http.ResponseWriter, r *http.Request) (WebSocket, error)
+}
+
+// WebSocket is the server side of one WebSocket connection
+type WebSocket interface {
+	// ReadMessage returns the next text or binary message, io.EOF once the client closed
+	ReadMessage() ([]byte, error)
+	// WriteText sends a text message; safe for concurrent use
+	WriteText(data []byte) error
+	Ping() error
+	// SetIdleTimeout makes ReadMessage fail when the client sends nothing for longer than d
+	SetIdleTimeout(d time.Duration)
+	Close() error
+}
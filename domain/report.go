@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Report reasons a reader can pick from
+var ReportReasons = []string{"spam", "harassment", "hate", "violence", "sexual", "misinformation", "other"}
+
+// Report triage states
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"  // report upheld, post taken down
+	ReportStatusDismissed = "dismissed" // report rejected
+)
+
+var (
+	ErrReportNotFound  = errors.New("report not found")
+	ErrAlreadyReported = errors.New("you have already reported this blog")
+)
+
+// Report represents a reader flagging a blog post as abusive
+type Report struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	BlogID     primitive.ObjectID  `json:"blog_id" bson:"blog_id"`
+	ReporterID primitive.ObjectID  `json:"reporter_id" bson:"reporter_id"`
+	Reason     string              `json:"reason" bson:"reason"`
+	Details    string              `json:"details" bson:"details"`
+	Status     string              `json:"status" bson:"status"`
+	CreatedAt  time.Time           `json:"created_at" bson:"created_at"`
+	ResolvedBy *primitive.ObjectID `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	ResolvedAt *time.Time          `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	Note       string              `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+// ReportRepository defines repository operations for content reports
+type ReportRepository interface {
+	CreateReport(report *Report) (*Report, error)
+	GetReportByID(id primitive.ObjectID) (*Report, error)
+	GetReports(status string) ([]*Report, error)
+	UpdateReportStatus(id primitive.ObjectID, status string, adminID primitive.ObjectID, note string) error
+
+	// Threshold checks
+	CountReports(blogID primitive.ObjectID, status string) (int64, error)
+	HasOpenReport(reporterID, blogID primitive.ObjectID) (bool, error)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrEmptySearchQuery = errors.New("search query is required")
+
+// SearchHit is one ranked search result
+type SearchHit struct {
+	Blog       *Blog       `json:"blog"`
+	Score      float64     `json:"score"`
+	Highlights *Highlights `json:"highlights,omitempty"`
+}
+
+// Highlights are HTML-escaped excerpts with matched terms wrapped in <mark> tags
+type Highlights struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchEngine indexes blogs and answers ranked free-text queries
+type SearchEngine interface {
+	Index(blog *Blog) error
+	Remove(blogID primitive.ObjectID) error
+	Search(terms []string, page PageRequest) (*Page[*SearchHit], error)
+
+	// Suggest completes a prefix to indexed words, most common first
+	Suggest(prefix string, limit int) ([]string, error)
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxSitemapURLs is how many URLs one sitemap file may list; beyond it /sitemap.xml becomes an index
+const MaxSitemapURLs = 50000
+
+var ErrSitemapNotFound = errors.New("sitemap not found")
+
+// SitemapDocument is a rendered sitemap or sitemap index
+type SitemapDocument struct {
+	Body     []byte
+	Modified time.Time // when the document was last regenerated
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Syndication feed formats
+const (
+	FeedFormatRSS  = "rss"
+	FeedFormatAtom = "atom"
+	FeedFormatJSON = "json" // JSON Feed 1.1
+)
+
+// How much of each post a feed carries
+const (
+	FeedContentFull    = "full"
+	FeedContentExcerpt = "excerpt"
+)
+
+var ErrInvalidFeedMode = errors.New("mode must be full or excerpt")
+
+// FeedRenderer serializes a feed in one of the feed formats
+type FeedRenderer interface {
+	// RenderFeed returns the document with its content type
+	RenderFeed(feed *Feed, format string) ([]byte, string, error)
+}
+
+// Feed is a format-independent syndication feed of published posts, newest first
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // the page the feed mirrors
+	URL         string // the feed's own address without the format extension, e.g. .../tags/go/feed
+	Query       string // what follows the extension in the feed's address, e.g. ?mode=excerpt
+	Updated     time.Time
+	Items       []*FeedItem
+}
+
+// SelfURL is the feed's own address in the format with the given extension, e.g. ".rss"
+func (f *Feed) SelfURL(ext string) string {
+	return f.URL + ext + f.Query
+}
+
+// FeedItem is one post in a feed; Content is the full text or an excerpt depending on the mode
+type FeedItem struct {
+	Title     string
+	Link      string // the post's permalink, which also serves as its id
+	Author    string
+	Tags      []string
+	Content   string
+	Excerpt   bool
+	Published time.Time
+	Updated   time.Time
+}
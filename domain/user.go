@@ -20,7 +20,7 @@ type UserRepository interface {
 	Create(user *User) (primitive.ObjectID, error)
 	Authenticate(usernameOrEmail, password string) (*User, error)
 	GetByID(id primitive.ObjectID) (*User, error)
-	GetAll() ([]*User, error)
+	GetAll(page PageRequest) (*Page[*User], error)
 	PromoteUser(userID primitive.ObjectID, newRole string) error
 	DeleteByID(id primitive.ObjectID) error
 	ChangePassword(id primitive.ObjectID, oldPassword string, newPassword string) error
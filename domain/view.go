@@ -0,0 +1,9 @@
+package domain
+
+// Viewer identifies who is reading a post, for counting each reader once
+type Viewer struct {
+	UserID    string // set for signed-in readers
+	IP        string
+	UserAgent string
+	Referrer  string
+}
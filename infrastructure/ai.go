@@ -67,3 +67,61 @@ func (s *MistralAIService) Suggest(prompt string) (string, error) {
 
     return parsed.Choices[0].Message.Content, nil
 }
+
+// Model reports the embedding model used by Embed
+func (s *MistralAIService) Model() string {
+    return "mistral-embed"
+}
+
+// Embed returns one mistral-embed vector per input text
+func (s *MistralAIService) Embed(texts []string) ([][]float32, error) {
+    apiKey := os.Getenv("M_API_KEY")
+    if apiKey == "" {
+        return nil, errors.New("AI not configured")
+    }
+
+    b, _ := json.Marshal(map[string]interface{}{
+        "model": s.Model(),
+        "input": texts,
+    })
+
+    client := &http.Client{Timeout: 30 * time.Second}
+    req, err := http.NewRequest("POST", "https://api.mistral.ai/v1/embeddings", bytes.NewBuffer(b))
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Authorization", "Bearer "+apiKey)
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("AI provider returned status %d", resp.StatusCode)
+    }
+
+    var parsed struct {
+        Data []struct {
+            Index     int       `json:"index"`
+            Embedding []float32 `json:"embedding"`
+        } `json:"data"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+        return nil, err
+    }
+    if len(parsed.Data) != len(texts) {
+        return nil, errors.New("AI provider returned the wrong number of embeddings")
+    }
+
+    vectors := make([][]float32, len(texts))
+    for _, d := range parsed.Data {
+        if d.Index < 0 || d.Index >= len(texts) {
+            return nil, errors.New("AI provider returned an unexpected embedding index")
+        }
+        vectors[d.Index] = d.Embedding
+    }
+    return vectors, nil
+}
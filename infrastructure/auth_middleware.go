@@ -30,33 +30,80 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenStr := parts[1]
-
-		// ✅ Parse token
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		userID, err := ParseToken(parts[1])
+		if err != nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			ctx.Abort()
 			return
 		}
+		if userID != "" {
+			ctx.Set("userID", userID)
+		}
 
-		// ✅ Extract user ID from claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			if userID, ok := claims["user_id"].(string); ok {
+		ctx.Next()
+	}
+}
+
+// OptionalAuth sets userID like AuthMiddleware when a valid bearer token is sent,
+// and lets the request through anonymously otherwise
+func OptionalAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parts := strings.Split(ctx.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if userID, err := ParseToken(parts[1]); err == nil && userID != "" {
 				ctx.Set("userID", userID)
 			}
 		}
+		ctx.Next()
+	}
+}
 
+// StreamAuth authenticates long-lived streams. Browsers cannot set headers on EventSource
+// or WebSocket requests, so the token may also come as ?access_token=. Anonymous clients
+// are let through, but a token that does not verify is rejected.
+func StreamAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := ctx.Query("access_token")
+		if parts := strings.Split(ctx.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+			token = parts[1]
+		}
+		if token != "" {
+			userID, err := ParseToken(token)
+			if err != nil {
+				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+				ctx.Abort()
+				return
+			}
+			if userID != "" {
+				ctx.Set("userID", userID)
+			}
+		}
 		ctx.Next()
 	}
 }
 
+// ParseToken validates a JWT and returns the user id it was issued for
+func ParseToken(tokenStr string) (string, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return "", jwt.ErrSignatureInvalid
+	}
+
+	// ✅ Extract user ID from claims
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if userID, ok := claims["user_id"].(string); ok {
+			return userID, nil
+		}
+	}
+	return "", nil
+}
+
 func AdminOnly(userUsecase *usecases.UserUsecase) gin.HandlerFunc {
     return func(ctx *gin.Context) {
         userIDstr, exists := ctx.Get("userID")
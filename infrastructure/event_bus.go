@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"log"
+	"sync"
+	"time"
+)
+
+// InMemoryEventBus dispatches events synchronously to in-process subscribers, so
+// handlers should be quick and hand slow work off to their own goroutines
+type InMemoryEventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]func(domain.Event)
+}
+
+func NewInMemoryEventBus() *InMemoryEventBus {
+	return &InMemoryEventBus{handlers: map[string][]func(domain.Event){}}
+}
+
+func (b *InMemoryEventBus) Subscribe(eventType string, handler func(domain.Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+func (b *InMemoryEventBus) Publish(e domain.Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := append([]func(domain.Event){}, b.handlers[e.Type]...)
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		dispatch(h, e)
+	}
+}
+
+// a failing subscriber must not break the request that published the event
+func dispatch(h func(domain.Event), e domain.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("event handler for %s panicked: %v", e.Type, r)
+		}
+	}()
+	h(e)
+}
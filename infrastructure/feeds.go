@@ -0,0 +1,262 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"html"
+	"strings"
+	"time"
+)
+
+var ErrUnknownFeedFormat = errors.New("unknown feed format")
+
+// FeedRenderer is the domain.FeedRenderer over RenderFeed
+type FeedRenderer struct{}
+
+func NewFeedRenderer() *FeedRenderer {
+	return &FeedRenderer{}
+}
+
+func (FeedRenderer) RenderFeed(feed *domain.Feed, format string) ([]byte, string, error) {
+	return RenderFeed(feed, format)
+}
+
+// RenderFeed serializes a feed as RSS 2.0, Atom or JSON Feed 1.1 and returns it with its content type
+func RenderFeed(feed *domain.Feed, format string) ([]byte, string, error) {
+	var (
+		body []byte
+		err  error
+	)
+	switch format {
+	case domain.FeedFormatRSS:
+		body, err = renderRSS(feed)
+		return body, "application/rss+xml; charset=utf-8", err
+	case domain.FeedFormatAtom:
+		body, err = renderAtom(feed)
+		return body, "application/atom+xml; charset=utf-8", err
+	case domain.FeedFormatJSON:
+		body, err = renderJSONFeed(feed)
+		return body, "application/feed+json; charset=utf-8", err
+	default:
+		return nil, "", ErrUnknownFeedFormat
+	}
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator"` // RSS's own author element wants an email address
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+func renderRSS(feed *domain.Feed) ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Description,
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+			Self:          atomLink{Href: feed.SelfURL(".rss"), Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, item := range feed.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.Link},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Categories:  item.Tags,
+			Description: itemHTML(item),
+		})
+	}
+	return marshalXML(doc)
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	ID       string      `xml:"id"`
+	Links    []atomLink  `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     string         `xml:"author>name"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+func renderAtom(feed *domain.Feed) ([]byte, error) {
+	doc := atomFeed{
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		ID:       feed.SelfURL(".atom"),
+		Links: []atomLink{
+			{Href: feed.Link, Rel: "alternate"},
+			{Href: feed.SelfURL(".atom"), Rel: "self", Type: "application/atom+xml"},
+		},
+		Updated: feed.Updated.UTC().Format(time.RFC3339),
+	}
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Link:      atomLink{Href: item.Link, Rel: "alternate"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Author:    item.Author,
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		if item.Excerpt {
+			entry.Summary = &atomText{Type: "text", Value: item.Content}
+		} else {
+			entry.Content = &atomText{Type: "html", Value: itemHTML(item)}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalXML(doc)
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+func renderJSONFeed(feed *domain.Feed) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.SelfURL(".json"),
+		Description: feed.Description,
+		Items:       []jsonFeedItem{},
+	}
+	for _, item := range feed.Items {
+		entry := jsonFeedItem{
+			ID:            item.Link,
+			URL:           item.Link,
+			Title:         item.Title,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{{Name: item.Author}},
+			Tags:          item.Tags,
+		}
+		if item.Excerpt {
+			entry.ContentText = item.Content
+		} else {
+			entry.ContentHTML = itemHTML(item)
+		}
+		doc.Items = append(doc.Items, entry)
+	}
+	// the HTML in content_html reads better unescaped
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalXML(doc any) ([]byte, error) {
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// itemHTML turns a post's plain text into HTML paragraphs; excerpts stay a single paragraph
+func itemHTML(item *domain.FeedItem) string {
+	if item.Excerpt {
+		return html.EscapeString(item.Content)
+	}
+	var b strings.Builder
+	for _, paragraph := range strings.Split(strings.ReplaceAll(item.Content, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+type ipRange struct {
+	start, end netip.Addr
+	country    string
+}
+
+// CSVGeoIP resolves countries from a local range database in the common
+// "start_ip,end_ip,country_code" CSV layout (for example the free DB-IP country lite
+// download), covering both IPv4 and IPv6. The whole file is held in memory.
+type CSVGeoIP struct {
+	ranges []ipRange // sorted by start
+}
+
+func NewCSVGeoIP(path string) (*CSVGeoIP, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	g := &CSVGeoIP{}
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("geoip %s line %d: expected start,end,country", path, line)
+		}
+		start, err1 := netip.ParseAddr(strings.TrimSpace(rec[0]))
+		end, err2 := netip.ParseAddr(strings.TrimSpace(rec[1]))
+		if err1 != nil || err2 != nil {
+			// tolerate a header row
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("geoip %s line %d: invalid address", path, line)
+		}
+		g.ranges = append(g.ranges, ipRange{
+			start:   start.Unmap(),
+			end:     end.Unmap(),
+			country: strings.ToUpper(strings.TrimSpace(rec[2])),
+		})
+	}
+
+	sort.Slice(g.ranges, func(i, j int) bool { return g.ranges[i].start.Less(g.ranges[j].start) })
+	return g, nil
+}
+
+// Country returns the ISO code of the range containing ip, or "" when none does
+func (g *CSVGeoIP) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// last range starting at or before addr
+	i := sort.Search(len(g.ranges), func(i int) bool { return addr.Less(g.ranges[i].start) }) - 1
+	if i < 0 {
+		return ""
+	}
+	r := g.ranges[i]
+	if r.start.BitLen() != addr.BitLen() || r.end.Less(addr) {
+		return ""
+	}
+	return r.country
+}
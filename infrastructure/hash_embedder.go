@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+)
+
+// HashEmbedder is a deterministic local stand-in for a real embedding model. Stemmed words
+// are hashed into a fixed number of buckets, so texts sharing vocabulary end up close
+// together; it needs no network access and is meant for development and tests.
+type HashEmbedder struct {
+	dims int
+}
+
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = 256
+	}
+	return &HashEmbedder{dims: dims}
+}
+
+func (e *HashEmbedder) Model() string {
+	return "hash-" + strconv.Itoa(e.dims)
+}
+
+func (e *HashEmbedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, e.dims)
+		for _, w := range tokenize(text) {
+			h := fnv.New32a()
+			h.Write([]byte(Stem(w)))
+			sum := h.Sum32()
+			// the top bit picks the sign so unrelated words tend to cancel out
+			if sum&(1<<31) != 0 {
+				v[sum%uint32(e.dims)]--
+			} else {
+				v[sum%uint32(e.dims)]++
+			}
+		}
+		normalize(v)
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// normalize scales v to unit length in place
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
@@ -0,0 +1,328 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // registered for Decode
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/webp"
+)
+
+// quality used for every JPEG the processor writes
+const jpegQuality = 85
+
+// edge length blurhash placeholders are computed from; more detail would not show
+const blurhashSource = 32
+
+// imageSize is one variant the processor renders
+type imageSize struct {
+	name   string
+	width  int  // bounding box; variants keep the aspect ratio
+	height int  // 0 for no height limit
+	crop   bool // fill the box exactly, cutting off what does not fit
+}
+
+var imageSizes = []imageSize{
+	{name: domain.VariantThumbnail, width: 200, height: 200, crop: true},
+	{name: domain.VariantMedium, width: 800},
+	{name: domain.VariantLarge, width: 1600},
+}
+
+// StdImageProcessor handles JPEG, PNG, GIF and WebP images. JPEG and PNG originals are
+// re-encoded so EXIF and other metadata are dropped, after turning them the way their EXIF
+// orientation says. WebPs cannot be encoded, so their EXIF and XMP chunks are cut out instead.
+// GIFs carry no EXIF and are kept as they are so animations survive; animated WebPs are
+// stripped too but get no variants, as the decoder only reads still images.
+type StdImageProcessor struct{}
+
+func NewStdImageProcessor() *StdImageProcessor {
+	return &StdImageProcessor{}
+}
+
+func (p *StdImageProcessor) Supports(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+func (p *StdImageProcessor) Process(data []byte, contentType string) (*domain.ProcessedImage, error) {
+	if !p.Supports(contentType) {
+		return nil, domain.ErrUnsupportedMediaType
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > domain.MaxImagePixels {
+		return nil, domain.ErrMediaTooLarge
+	}
+
+	orientation := 1
+	var stripped []byte
+	switch contentType {
+	case "image/jpeg":
+		orientation = jpegOrientation(data)
+	case "image/webp":
+		var animated bool
+		if stripped, orientation, animated, err = stripWebPMetadata(data); err != nil {
+			return nil, err
+		}
+		if animated {
+			return &domain.ProcessedImage{Original: stripped, Width: config.Width, Height: config.Height}, nil
+		}
+	}
+
+	var decoded image.Image
+	if contentType == "image/webp" {
+		decoded, err = webp.Decode(bytes.NewReader(data))
+	} else {
+		decoded, _, err = image.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	img := orient(toNRGBA(decoded), orientation)
+
+	// variants of photos are JPEGs, everything that may be transparent gets PNGs
+	variantType := "image/png"
+	if _, opaque := decoded.(*image.YCbCr); contentType == "image/jpeg" || opaque {
+		variantType = "image/jpeg"
+	}
+
+	result := &domain.ProcessedImage{Width: img.Rect.Dx(), Height: img.Rect.Dy()}
+	switch contentType {
+	case "image/jpeg", "image/png":
+		if result.Original, err = encodeImage(img, contentType); err != nil {
+			return nil, err
+		}
+	case "image/webp":
+		result.Original = stripped
+	}
+	for _, size := range imageSizes {
+		variant := resizeTo(img, size)
+		if variant == nil {
+			continue
+		}
+		encoded, err := encodeImage(variant, variantType)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, domain.ProcessedVariant{
+			Name:        size.name,
+			ContentType: variantType,
+			Data:        encoded,
+			Width:       variant.Rect.Dx(),
+			Height:      variant.Rect.Dy(),
+		})
+	}
+	small := resizeTo(img, imageSize{width: blurhashSource, height: blurhashSource})
+	if small == nil {
+		small = img
+	}
+	result.Blurhash = encodeBlurhash(small, 4, 3)
+	return result, nil
+}
+
+func encodeImage(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	default:
+		err = domain.ErrUnsupportedMediaType
+	}
+	return buf.Bytes(), err
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) {
+		return n
+	}
+	b := img.Bounds()
+	n := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(n, n.Rect, img, b.Min, draw.Src)
+	return n
+}
+
+// resizeTo scales img down into size's box; nil when the image is already no larger,
+// except for cropped sizes, which are always made (without scaling up)
+func resizeTo(img *image.NRGBA, size imageSize) *image.NRGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if size.crop {
+		// cut the largest centred region with the box's aspect ratio
+		cw, ch := w, w*size.height/size.width
+		if ch > h {
+			cw, ch = h*size.width/size.height, h
+		}
+		x0, y0 := (w-cw)/2, (h-ch)/2
+		img = img.SubImage(image.Rect(x0, y0, x0+cw, y0+ch)).(*image.NRGBA)
+		w, h = cw, ch
+		if w <= size.width {
+			return toNRGBA(img)
+		}
+		return resample(img, size.width, size.height)
+	}
+
+	tw, th := w, h
+	if tw > size.width {
+		tw, th = size.width, h*size.width/w
+	}
+	if size.height > 0 && th > size.height {
+		tw, th = w*size.height/h, size.height
+	}
+	if tw == w && th == h {
+		return nil
+	}
+	return resample(img, max(tw, 1), max(th, 1))
+}
+
+// resample shrinks img to w×h by averaging the source pixels under each target pixel,
+// weighting colour by alpha so transparent pixels do not bleed into their neighbours
+func resample(img *image.NRGBA, w, h int) *image.NRGBA {
+	sw, sh := img.Rect.Dx(), img.Rect.Dy()
+	ox, oy := img.Rect.Min.X, img.Rect.Min.Y
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, (y+1)*sh/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, (x+1)*sw/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				i := img.PixOffset(ox+x0, oy+sy)
+				for sx := x0; sx < x1; sx++ {
+					pa := uint64(img.Pix[i+3])
+					r += uint64(img.Pix[i]) * pa
+					g += uint64(img.Pix[i+1]) * pa
+					b += uint64(img.Pix[i+2]) * pa
+					a += pa
+					n++
+					i += 4
+				}
+			}
+			o := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[o] = uint8((r + a/2) / a)
+				dst.Pix[o+1] = uint8((g + a/2) / a)
+				dst.Pix[o+2] = uint8((b + a/2) / a)
+			}
+			dst.Pix[o+3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
+
+// orient turns an image upright according to its EXIF orientation (1-8)
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// the source pixel shown at (x, y) once upright
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // mirrored, rotated
+				sx, sy = y, x
+			case 6: // rotated 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // mirrored, rotated the other way
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], img.Pix[img.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, 1 (upright) when it has none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // image data starts, no EXIF before it
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			if o, err := exifOrientation(segment[6:]); err == nil {
+				return o
+			}
+			return 1
+		}
+		i = end
+	}
+	return 1
+}
+
+// exifOrientation finds tag 0x0112 in the first IFD of a TIFF structure
+func exifOrientation(tiff []byte) (int, error) {
+	errMalformed := errors.New("malformed exif")
+	if len(tiff) < 8 {
+		return 0, errMalformed
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, errMalformed
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, errMalformed
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		at := ifd + 2 + e*12
+		if at+12 > len(tiff) {
+			return 0, errMalformed
+		}
+		if order.Uint16(tiff[at:]) == 0x0112 {
+			return int(order.Uint16(tiff[at+8:])), nil
+		}
+	}
+	return 1, nil
+}
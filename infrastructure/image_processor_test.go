@@ -0,0 +1,188 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// a GPS position as cameras and phones write it into XMP
+const testXMP = `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+	`<rdf:Description xmlns:exif="http://ns.adobe.com/exif/1.0/" exif:GPSLatitude="52,31.2N" exif:GPSLongitude="13,24.6E"/>` +
+	`</rdf:RDF></x:xmpmeta>`
+
+// exifWithGPS is a little-endian TIFF structure holding an orientation and a GPS IFD
+func exifWithGPS(orientation int) []byte {
+	entry := func(tiff []byte, tag, kind uint16, count, value uint32) []byte {
+		tiff = binary.LittleEndian.AppendUint16(tiff, tag)
+		tiff = binary.LittleEndian.AppendUint16(tiff, kind)
+		tiff = binary.LittleEndian.AppendUint32(tiff, count)
+		return binary.LittleEndian.AppendUint32(tiff, value)
+	}
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = entry(tiff, 0x0112, 3, 1, uint32(orientation))
+	tiff = entry(tiff, 0x8825, 4, 1, 38) // GPS IFD, right after this one
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = entry(tiff, 0x0001, 2, 2, 'N') // GPSLatitudeRef
+	return binary.LittleEndian.AppendUint32(tiff, 0)
+}
+
+// testJPEG encodes a w×h photo and adds EXIF (with GPS) and XMP segments after the SOI marker
+func testJPEG(t *testing.T, w, h, orientation int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / w), uint8(y * 255 / h), 128, 255})
+		}
+	}
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	segment := func(payload []byte) []byte {
+		s := []byte{0xFF, 0xE1}
+		s = binary.BigEndian.AppendUint16(s, uint16(len(payload)+2))
+		return append(s, payload...)
+	}
+	data := append([]byte{}, encoded.Bytes()[:2]...)
+	data = append(data, segment(append([]byte("Exif\x00\x00"), exifWithGPS(orientation)...))...)
+	data = append(data, segment([]byte("http://ns.adobe.com/xap/1.0/\x00"+testXMP))...)
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+// testWebP wraps the 75×100 lossless gopher in an extended WebP with EXIF (with GPS) and XMP chunks
+func testWebP(t *testing.T, orientation int) []byte {
+	t.Helper()
+	src, err := os.ReadFile("testdata/gopher.lossless.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp8x := []byte{webpEXIFFlag | webpXMPFlag, 0, 0, 0}
+	vp8x = append(vp8x, 75-1, 0, 0, 100-1, 0, 0) // canvas size minus one, 24 bits each
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	data = appendWebPChunk(data, "VP8X", vp8x)
+	data = append(data, src[12:]...) // the VP8L chunk
+	data = appendWebPChunk(data, "EXIF", exifWithGPS(orientation))
+	data = appendWebPChunk(data, "XMP ", []byte(testXMP))
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	return data
+}
+
+// assertNoMetadata fails when any trace of the test EXIF or XMP is left in data
+func assertNoMetadata(t *testing.T, name string, data []byte) {
+	t.Helper()
+	for _, marker := range []string{"Exif", "XMP ", "xmpmeta", "GPSLatitude", "\x25\x88\x04\x00"} {
+		if bytes.Contains(data, []byte(marker)) {
+			t.Errorf("%s still contains %q", name, marker)
+		}
+	}
+}
+
+func TestProcessStripsJPEGMetadata(t *testing.T) {
+	data := testJPEG(t, 64, 48, 1)
+	if !bytes.Contains(data, []byte("GPSLatitude")) || jpegOrientation(data) != 1 {
+		t.Fatal("test image is missing its metadata")
+	}
+
+	result, err := NewStdImageProcessor().Process(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoMetadata(t, "original", result.Original)
+	for _, v := range result.Variants {
+		assertNoMetadata(t, v.Name, v.Data)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(result.Original)); err != nil {
+		t.Fatalf("stripped original does not decode: %v", err)
+	}
+}
+
+func TestProcessStripsWebPMetadata(t *testing.T) {
+	data := testWebP(t, 1)
+	if !bytes.Contains(data, []byte("GPSLatitude")) || !bytes.Contains(data, []byte("\x25\x88\x04\x00")) {
+		t.Fatal("test image is missing its metadata")
+	}
+
+	result, err := NewStdImageProcessor().Process(data, "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoMetadata(t, "original", result.Original)
+	if bytes.Contains(result.Original, []byte("EXIF")) {
+		t.Error("upright original kept an EXIF chunk")
+	}
+	if flags := result.Original[20]; flags&(webpEXIFFlag|webpXMPFlag) != 0 {
+		t.Errorf("VP8X still announces metadata: flags %#x", flags)
+	}
+	if size := binary.LittleEndian.Uint32(result.Original[4:]); int(size) != len(result.Original)-8 {
+		t.Errorf("RIFF size %d for %d bytes", size, len(result.Original))
+	}
+	if _, err := webp.Decode(bytes.NewReader(result.Original)); err != nil {
+		t.Fatalf("stripped original does not decode: %v", err)
+	}
+}
+
+func TestProcessKeepsOnlyTheWebPOrientation(t *testing.T) {
+	result, err := NewStdImageProcessor().Process(testWebP(t, 6), "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoMetadata(t, "original", result.Original)
+	stripped, orientation, _, err := stripWebPMetadata(result.Original)
+	if err != nil || orientation != 6 || !bytes.Equal(stripped, result.Original) {
+		t.Fatalf("orientation chunk does not read back: orientation %d, err %v", orientation, err)
+	}
+}
+
+func TestProcessSwapsSidesForRotatedImages(t *testing.T) {
+	p := NewStdImageProcessor()
+	for _, orientation := range []int{6, 8} {
+		result, err := p.Process(testJPEG(t, 64, 48, orientation), "image/jpeg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Width != 48 || result.Height != 64 {
+			t.Errorf("jpeg orientation %d: %d×%d, want 48×64", orientation, result.Width, result.Height)
+		}
+		config, err := jpeg.DecodeConfig(bytes.NewReader(result.Original))
+		if err != nil || config.Width != 48 || config.Height != 64 {
+			t.Errorf("jpeg orientation %d: original is %d×%d (%v), want 48×64", orientation, config.Width, config.Height, err)
+		}
+
+		// WebP originals are not rotated, only their size is reported upright
+		result, err = p.Process(testWebP(t, orientation), "image/webp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Width != 100 || result.Height != 75 {
+			t.Errorf("webp orientation %d: %d×%d, want 100×75", orientation, result.Width, result.Height)
+		}
+	}
+}
+
+func TestOrientTurnsImagesUpright(t *testing.T) {
+	red, blue := color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 255}
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, red)
+	img.SetNRGBA(1, 0, blue)
+
+	// orientation 6 stores the top of the picture in the first column, 8 in the last
+	for orientation, top := range map[int]color.NRGBA{6: red, 8: blue} {
+		upright := orient(img, orientation)
+		if upright.Rect.Dx() != 1 || upright.Rect.Dy() != 2 {
+			t.Fatalf("orientation %d: %v", orientation, upright.Rect)
+		}
+		if got := upright.NRGBAAt(0, 0); got != top {
+			t.Errorf("orientation %d: top pixel %v, want %v", orientation, got, top)
+		}
+	}
+}
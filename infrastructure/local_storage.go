@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalMediaStorage keeps media files in a directory on local disk
+type LocalMediaStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalMediaStorage stores files under dir; baseURL is where they are served from
+func NewLocalMediaStorage(dir, baseURL string) (*LocalMediaStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalMediaStorage{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// path maps a key into the storage directory; cleaning it as an absolute path keeps ".." from escaping
+func (s *LocalMediaStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put writes to a temporary file first so readers never see a partial upload
+func (s *LocalMediaStorage) Put(key string, body io.Reader, size int64, contentType string) error {
+	target := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *LocalMediaStorage) Open(key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrMediaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		f.Close()
+		return nil, domain.ErrMediaNotFound
+	}
+	return f, nil
+}
+
+func (s *LocalMediaStorage) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalMediaStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
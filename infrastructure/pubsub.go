@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"sync"
+)
+
+// messages buffered per subscription of the in-process hub
+const subscriptionBuffer = 64
+
+// InMemoryPubSub is a pub/sub hub for a single API instance. Publishing never blocks:
+// a subscriber whose buffer is full misses the message, which is fine for live counters
+// and notifications that are also persisted.
+type InMemoryPubSub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*memorySubscription]bool
+}
+
+func NewInMemoryPubSub() *InMemoryPubSub {
+	return &InMemoryPubSub{topics: map[string]map[*memorySubscription]bool{}}
+}
+
+type memorySubscription struct {
+	hub    *InMemoryPubSub
+	ch     chan domain.RealtimeMessage
+	topics map[string]bool // guarded by hub.mu
+	closed bool
+}
+
+func (h *InMemoryPubSub) Publish(topic string, msg domain.RealtimeMessage) {
+	msg.Topic = topic
+
+	// sending under the read lock keeps Close from closing a channel mid-send
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.ch <- msg:
+		default:
+		}
+	}
+}
+
+func (h *InMemoryPubSub) Subscribe(topics ...string) (domain.Subscription, error) {
+	sub := &memorySubscription{hub: h, ch: make(chan domain.RealtimeMessage, subscriptionBuffer), topics: map[string]bool{}}
+	for _, topic := range topics {
+		if err := sub.Add(topic); err != nil {
+			sub.Close()
+			return nil, err
+		}
+	}
+	return sub, nil
+}
+
+func (s *memorySubscription) Messages() <-chan domain.RealtimeMessage {
+	return s.ch
+}
+
+func (s *memorySubscription) Add(topic string) error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if s.closed || s.topics[topic] {
+		return nil
+	}
+	if len(s.topics) >= domain.MaxSubscriptionTopics {
+		return domain.ErrTooManyTopics
+	}
+	subs, ok := s.hub.topics[topic]
+	if !ok {
+		subs = map[*memorySubscription]bool{}
+		s.hub.topics[topic] = subs
+	}
+	subs[s] = true
+	s.topics[topic] = true
+	return nil
+}
+
+func (s *memorySubscription) Remove(topic string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.remove(topic)
+}
+
+// remove drops one topic; the caller holds hub.mu
+func (s *memorySubscription) remove(topic string) {
+	if !s.topics[topic] {
+		return
+	}
+	delete(s.topics, topic)
+	subs := s.hub.topics[topic]
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(s.hub.topics, topic)
+	}
+}
+
+func (s *memorySubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	for topic := range s.topics {
+		s.remove(topic)
+	}
+	s.closed = true
+	close(s.ch)
+}
@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit allows at most limit requests per window for each caller, keyed by the
+// authenticated user id when AuthMiddleware ran first and by client IP otherwise
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	type bucket struct {
+		count int
+		reset time.Time
+	}
+
+	var mu sync.Mutex
+	buckets := map[string]*bucket{}
+	lastSweep := time.Now()
+
+	return func(ctx *gin.Context) {
+		key := ctx.ClientIP()
+		if userID, ok := ctx.Get("userID"); ok {
+			if id, ok := userID.(string); ok && id != "" {
+				key = "user:" + id
+			}
+		}
+
+		now := time.Now()
+		mu.Lock()
+		// drop expired buckets now and then so the map does not grow forever
+		if now.Sub(lastSweep) > window {
+			for k, b := range buckets {
+				if now.After(b.reset) {
+					delete(buckets, k)
+				}
+			}
+			lastSweep = now
+		}
+
+		b, ok := buckets[key]
+		if !ok || now.After(b.reset) {
+			b = &bucket{reset: now.Add(window)}
+			buckets[key] = b
+		}
+		b.count++
+		allowed := b.count <= limit
+		retryAfter := b.reset.Sub(now)
+		mu.Unlock()
+
+		if !allowed {
+			ctx.Header("Retry-After", retryAfterSeconds(retryAfter))
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	secs := int(d.Seconds())
+	if d > time.Duration(secs)*time.Second {
+		secs++
+	}
+	return strconv.Itoa(secs)
+}
@@ -0,0 +1,314 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"encoding/base64"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// field weights: a term in the title counts as three occurrences in the body
+var fieldWeights = struct{ title, tags, author, content int }{title: 3, tags: 2, author: 2, content: 1}
+
+// words too common to be worth indexing
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "if": true, "in": true, "into": true, "is": true, "it": true, "no": true,
+	"not": true, "of": true, "on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true, "this": true, "to": true,
+	"was": true, "will": true, "with": true,
+}
+
+type indexedDoc struct {
+	blog   *domain.Blog
+	terms  map[string]int // stem -> weighted term frequency
+	words  map[string]bool
+	length int
+}
+
+// InMemorySearchEngine is an inverted index over blog posts held in process memory,
+// ranked with BM25 and supporting prefix completion for autocomplete
+type InMemorySearchEngine struct {
+	mu       sync.Mutex
+	docs     map[primitive.ObjectID]*indexedDoc
+	postings map[string]map[primitive.ObjectID]int // stem -> doc -> weighted tf
+	words    map[string]int                        // surface word -> document frequency
+	totalLen int
+
+	sortedWords []string // vocabulary for prefix lookups, rebuilt lazily
+	wordsDirty  bool
+}
+
+func NewInMemorySearchEngine() *InMemorySearchEngine {
+	return &InMemorySearchEngine{
+		docs:     map[primitive.ObjectID]*indexedDoc{},
+		postings: map[string]map[primitive.ObjectID]int{},
+		words:    map[string]int{},
+	}
+}
+
+// tokenize splits text into lowercase words, dropping stop words
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if !stopWords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// Index adds or replaces a blog; hidden blogs are removed instead
+func (e *InMemorySearchEngine) Index(blog *domain.Blog) error {
+	if blog == nil {
+		return nil
+	}
+	if blog.Status == domain.BlogStatusHidden {
+		return e.Remove(blog.ID)
+	}
+
+	doc := &indexedDoc{blog: blog, terms: map[string]int{}, words: map[string]bool{}}
+	add := func(text string, weight int) {
+		for _, w := range tokenize(text) {
+			doc.terms[Stem(w)] += weight
+			doc.words[w] = true
+			doc.length += weight
+		}
+	}
+	add(blog.Title, fieldWeights.title)
+	add(strings.Join(blog.Tags, " "), fieldWeights.tags)
+	add(blog.AuthorName, fieldWeights.author)
+	add(blog.Content, fieldWeights.content)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.removeLocked(blog.ID)
+	e.docs[blog.ID] = doc
+	e.totalLen += doc.length
+	for term, tf := range doc.terms {
+		if e.postings[term] == nil {
+			e.postings[term] = map[primitive.ObjectID]int{}
+		}
+		e.postings[term][blog.ID] = tf
+	}
+	for w := range doc.words {
+		if e.words[w] == 0 {
+			e.wordsDirty = true
+		}
+		e.words[w]++
+	}
+	return nil
+}
+
+func (e *InMemorySearchEngine) Remove(blogID primitive.ObjectID) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.removeLocked(blogID)
+	return nil
+}
+
+func (e *InMemorySearchEngine) removeLocked(blogID primitive.ObjectID) {
+	doc, ok := e.docs[blogID]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(e.postings[term], blogID)
+		if len(e.postings[term]) == 0 {
+			delete(e.postings, term)
+		}
+	}
+	for w := range doc.words {
+		e.words[w]--
+		if e.words[w] <= 0 {
+			delete(e.words, w)
+			e.wordsDirty = true
+		}
+	}
+	e.totalLen -= doc.length
+	delete(e.docs, blogID)
+}
+
+// Search scores every document containing a query term with BM25. The last term is also
+// matched as a prefix so results keep up while the user is still typing.
+func (e *InMemorySearchEngine) Search(terms []string, page domain.PageRequest) (*domain.Page[*domain.SearchHit], error) {
+	page = page.Normalize()
+
+	var queryWords []string
+	for _, t := range terms {
+		queryWords = append(queryWords, tokenize(t)...)
+	}
+	if len(queryWords) == 0 {
+		return nil, domain.ErrEmptySearchQuery
+	}
+
+	var after *scoredDoc
+	if page.Cursor != "" {
+		c, err := decodeScoreCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refreshWordsLocked()
+
+	// query stems with their weight; prefix expansions count for half
+	stems := map[string]float64{}
+	for _, w := range queryWords {
+		stems[Stem(w)] = 1
+	}
+	last := queryWords[len(queryWords)-1]
+	for _, w := range e.wordsWithPrefixLocked(last, 20) {
+		if s := Stem(w); stems[s] == 0 {
+			stems[s] = 0.5
+		}
+	}
+
+	n := float64(len(e.docs))
+	avgLen := 1.0
+	if len(e.docs) > 0 {
+		avgLen = float64(e.totalLen) / n
+	}
+
+	scores := map[primitive.ObjectID]float64{}
+	for stem, qw := range stems {
+		posting := e.postings[stem]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			f := float64(tf)
+			dl := float64(e.docs[id].length)
+			scores[id] += qw * idf * (f * (bm25K1 + 1)) / (f + bm25K1*(1-bm25B+bm25B*dl/avgLen))
+		}
+	}
+
+	ranked := make([]scoredDoc, 0, len(scores))
+	for id, score := range scores {
+		// round so cursors survive the trip through text
+		ranked = append(ranked, scoredDoc{id: id, score: math.Round(score*1e6) / 1e6})
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].before(ranked[j]) })
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(ranked), func(i int) bool { return after.before(ranked[i]) })
+	}
+
+	result := &domain.Page[*domain.SearchHit]{Items: []*domain.SearchHit{}, Limit: page.Limit}
+	for i := start; i < len(ranked) && len(result.Items) < page.Limit; i++ {
+		blog := *e.docs[ranked[i].id].blog
+		result.Items = append(result.Items, &domain.SearchHit{Blog: &blog, Score: ranked[i].score})
+	}
+	if end := start + len(result.Items); end < len(ranked) && len(result.Items) > 0 {
+		result.HasMore = true
+		result.NextCursor = encodeScoreCursor(ranked[end-1])
+	}
+	return result, nil
+}
+
+// Suggest returns indexed words starting with prefix, most common first
+func (e *InMemorySearchEngine) Suggest(prefix string, limit int) ([]string, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return []string{}, nil
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refreshWordsLocked()
+
+	matches := e.wordsWithPrefixLocked(prefix, -1)
+	sort.SliceStable(matches, func(i, j int) bool { return e.words[matches[i]] > e.words[matches[j]] })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (e *InMemorySearchEngine) refreshWordsLocked() {
+	if !e.wordsDirty && e.sortedWords != nil {
+		return
+	}
+	e.sortedWords = make([]string, 0, len(e.words))
+	for w := range e.words {
+		e.sortedWords = append(e.sortedWords, w)
+	}
+	sort.Strings(e.sortedWords)
+	e.wordsDirty = false
+}
+
+// wordsWithPrefixLocked binary-searches the vocabulary; max < 0 means no limit
+func (e *InMemorySearchEngine) wordsWithPrefixLocked(prefix string, max int) []string {
+	var out []string
+	i := sort.SearchStrings(e.sortedWords, prefix)
+	for ; i < len(e.sortedWords) && strings.HasPrefix(e.sortedWords[i], prefix); i++ {
+		out = append(out, e.sortedWords[i])
+		if max > 0 && len(out) == max {
+			break
+		}
+	}
+	return out
+}
+
+type scoredDoc struct {
+	id    primitive.ObjectID
+	score float64
+}
+
+// before orders by score descending, then id descending
+func (d scoredDoc) before(o scoredDoc) bool {
+	if d.score != o.score {
+		return d.score > o.score
+	}
+	return d.id.Hex() > o.id.Hex()
+}
+
+func encodeScoreCursor(d scoredDoc) string {
+	raw := strconv.FormatFloat(d.score, 'g', -1, 64) + ":" + d.id.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeScoreCursor(cursor string) (*scoredDoc, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, domain.ErrInvalidCursor
+	}
+	score, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &scoredDoc{id: id, score: score}, nil
+}
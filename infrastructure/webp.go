@@ -0,0 +1,96 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// VP8X header flags
+const (
+	webpAnimationFlag = 1 << 1
+	webpXMPFlag       = 1 << 2
+	webpEXIFFlag      = 1 << 3
+)
+
+var errMalformedWebP = errors.New("malformed webp")
+
+// stripWebPMetadata drops the EXIF and XMP chunks of a WebP file. WebPs cannot be re-encoded
+// here, so the orientation is the one thing kept from EXIF: when the image is not upright, a
+// minimal EXIF chunk holding only the orientation takes the old one's place.
+func stripWebPMetadata(data []byte) (stripped []byte, orientation int, animated bool, err error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, 0, false, errMalformedWebP
+	}
+	orientation = 1
+	var out bytes.Buffer
+	out.Write(data[:12])
+	vp8x := -1 // where the VP8X payload starts in out
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, 0, false, errMalformedWebP
+		}
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size
+		if size < 0 || end > len(data) {
+			return nil, 0, false, errMalformedWebP
+		}
+		payload := data[i+8 : end]
+		if size%2 == 1 && end < len(data) {
+			end++ // chunks are padded to an even length
+		}
+
+		switch fourCC {
+		case "EXIF":
+			tiff := bytes.TrimPrefix(payload, []byte("Exif\x00\x00"))
+			if o, err := exifOrientation(tiff); err == nil && o >= 1 && o <= 8 {
+				orientation = o
+			}
+		case "XMP ":
+		default:
+			if fourCC == "VP8X" {
+				if size < 10 {
+					return nil, 0, false, errMalformedWebP
+				}
+				vp8x = out.Len() + 8
+				animated = payload[0]&webpAnimationFlag != 0
+			}
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+
+	stripped = out.Bytes()
+	if vp8x >= 0 {
+		stripped[vp8x] &^= webpEXIFFlag | webpXMPFlag
+		if orientation != 1 {
+			stripped[vp8x] |= webpEXIFFlag
+			stripped = appendWebPChunk(stripped, "EXIF", orientationEXIF(orientation))
+		}
+	}
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, orientation, animated, nil
+}
+
+func appendWebPChunk(data []byte, fourCC string, payload []byte) []byte {
+	data = append(data, fourCC...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(payload)))
+	data = append(data, payload...)
+	if len(payload)%2 == 1 {
+		data = append(data, 0)
+	}
+	return data
+}
+
+// orientationEXIF is a little-endian TIFF structure with a single IFD holding only the orientation tag
+func orientationEXIF(orientation int) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)      // one entry
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112) // orientation
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)      // one value
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0)                        // value padding
+	return binary.LittleEndian.AppendUint32(tiff, 0) // no next IFD
+}
@@ -0,0 +1,277 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes (RFC 6455 section 5.2)
+const (
+	WebSocketText   = 0x1
+	WebSocketBinary = 0x2
+	WebSocketClose  = 0x8
+	WebSocketPing   = 0x9
+	WebSocketPong   = 0xA
+
+	wsContinuation = 0x0
+	wsGUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// MaxWebSocketMessage bounds what a client may send in one message
+const MaxWebSocketMessage = 64 << 10
+
+var (
+	ErrWebSocketProtocol = errors.New("websocket protocol error")
+	ErrWebSocketTooLarge = errors.New("websocket message too large")
+	ErrWebSocketClosed   = errors.New("websocket closed")
+)
+
+// WebSocketUpgrader is the domain.WebSocketUpgrader over WebSocketConn
+type WebSocketUpgrader struct{}
+
+func NewWebSocketUpgrader() *WebSocketUpgrader {
+	return &WebSocketUpgrader{}
+}
+
+func (WebSocketUpgrader) Upgrade(w http.ResponseWriter, r *http.Request) (domain.WebSocket, error) {
+	conn, err := UpgradeWebSocket(w, r)
+	if err != nil {
+		return nil, err
+	}
+	return webSocket{conn}, nil
+}
+
+// webSocket narrows a WebSocketConn to the text messages domain.WebSocket deals in
+type webSocket struct {
+	*WebSocketConn
+}
+
+func (s webSocket) ReadMessage() ([]byte, error) {
+	_, data, err := s.WebSocketConn.ReadMessage()
+	return data, err
+}
+
+func (s webSocket) WriteText(data []byte) error {
+	return s.WriteMessage(WebSocketText, data)
+}
+
+func (s webSocket) Ping() error {
+	return s.WriteMessage(WebSocketPing, nil)
+}
+
+// WebSocketConn is a minimal server side WebSocket connection: unfragmented writes,
+// reassembled reads, and automatic replies to pings and close frames
+type WebSocketConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	idle time.Duration
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// UpgradeWebSocket completes the opening handshake and takes over the connection
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocketConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		return nil, domain.ErrNotWebSocket
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("connection does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + wsGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WebSocketConn{conn: conn, rw: rw}, nil
+}
+
+// headerContains checks a comma-separated header for a token, case-insensitively
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteMessage sends one unfragmented frame; safe for concurrent use
+func (c *WebSocketConn) WriteMessage(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closed {
+		return ErrWebSocketClosed
+	}
+	header := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// ReadMessage returns the next text or binary message. Pings are answered and a close
+// frame is echoed before io.EOF is returned.
+func (c *WebSocketConn) ReadMessage() (byte, []byte, error) {
+	var opcode byte
+	var message []byte
+	for {
+		if c.idle > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.idle))
+		}
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case WebSocketPing:
+			if err := c.WriteMessage(WebSocketPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case WebSocketPong:
+			continue
+		case WebSocketClose:
+			c.closeWith(payload)
+			return 0, nil, io.EOF
+		case WebSocketText, WebSocketBinary:
+			if opcode != 0 {
+				return 0, nil, ErrWebSocketProtocol
+			}
+			opcode = op
+		case wsContinuation:
+			if opcode == 0 {
+				return 0, nil, ErrWebSocketProtocol
+			}
+		default:
+			return 0, nil, ErrWebSocketProtocol
+		}
+
+		if len(message)+len(payload) > MaxWebSocketMessage {
+			return 0, nil, ErrWebSocketTooLarge
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *WebSocketConn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	op := head[0] & 0x0F
+	if head[0]&0x70 != 0 || head[1]&0x80 == 0 {
+		// no extensions were negotiated, and clients must mask their frames
+		return false, 0, nil, ErrWebSocketProtocol
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if op >= WebSocketClose && (length > 125 || !fin) {
+		return false, 0, nil, ErrWebSocketProtocol
+	}
+	if length > MaxWebSocketMessage {
+		return false, 0, nil, ErrWebSocketTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+// SetIdleTimeout makes ReadMessage fail when the client sends nothing, not even a pong,
+// for longer than d
+func (c *WebSocketConn) SetIdleTimeout(d time.Duration) {
+	c.idle = d
+}
+
+// Close sends a normal closure frame and closes the connection
+func (c *WebSocketConn) Close() error {
+	return c.closeWith([]byte{0x03, 0xE8}) // 1000, normal closure
+}
+
+func (c *WebSocketConn) closeWith(payload []byte) error {
+	if len(payload) > 2 {
+		// echo the status code only
+		payload = payload[:2]
+	}
+	c.WriteMessage(WebSocketClose, payload)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
@@ -0,0 +1,303 @@
+package infrastructure
+
+import (
+	"Blog/domain"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pipeWebSocket is a server side connection whose client end the test drives by hand
+func pipeWebSocket(t *testing.T) (*WebSocketConn, net.Conn) {
+	t.Helper()
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	server.SetDeadline(deadline)
+	client.SetDeadline(deadline)
+	rw := bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))
+	return &WebSocketConn{conn: server, rw: rw}, client
+}
+
+// clientFrame builds a masked frame as a browser would send it
+func clientFrame(fin bool, opcode byte, mask [4]byte, payload []byte) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = binary.BigEndian.AppendUint16(append(frame, 0x80|126), uint16(n))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, 0x80|127), uint64(n))
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+// send writes frames from the client end without blocking the test on the pipe
+func send(client net.Conn, frames ...[]byte) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Write(bytes.Join(frames, nil))
+		done <- err
+	}()
+	return done
+}
+
+// readServerFrame reads one unmasked frame written by the server
+func readServerFrame(t *testing.T, r io.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatalf("reading frame header: %v", err)
+	}
+	if head[0]&0x80 == 0 {
+		t.Fatalf("server frame without FIN: %#x", head[0])
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("server frames must not be masked")
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("reading frame payload: %v", err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func TestWebSocketUnmasksClientFrames(t *testing.T) {
+	ws, client := pipeWebSocket(t)
+	// the masked "Hello" of RFC 6455 section 5.7
+	send(client, []byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58})
+
+	opcode, data, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != WebSocketText || string(data) != "Hello" {
+		t.Fatalf("got opcode %#x %q, want text %q", opcode, data, "Hello")
+	}
+}
+
+func TestWebSocketReadsExtendedLengths(t *testing.T) {
+	for _, n := range []int{125, 126, 300, 0xFFFF, 0x10000} {
+		ws, client := pipeWebSocket(t)
+		payload := bytes.Repeat([]byte("abcdefg"), n/7+1)[:n]
+		send(client, clientFrame(true, WebSocketBinary, [4]byte{1, 2, 3, 4}, payload))
+
+		opcode, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if opcode != WebSocketBinary || !bytes.Equal(data, payload) {
+			t.Fatalf("%d bytes: payload was not read back", n)
+		}
+	}
+}
+
+func TestWebSocketReassemblesFragmentsAndAnswersPings(t *testing.T) {
+	ws, client := pipeWebSocket(t)
+	mask := [4]byte{0xa1, 0xb2, 0xc3, 0xd4}
+	send(client,
+		clientFrame(false, WebSocketText, mask, []byte("Hel")),
+		clientFrame(true, WebSocketPing, mask, []byte("are you there")),
+		clientFrame(true, wsContinuation, mask, []byte("lo")),
+	)
+	pong := make(chan []byte, 1)
+	go func() {
+		opcode, payload := readServerFrame(t, client)
+		if opcode != WebSocketPong {
+			payload = nil
+		}
+		pong <- payload
+	}()
+
+	opcode, data, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != WebSocketText || string(data) != "Hello" {
+		t.Fatalf("got opcode %#x %q, want text %q", opcode, data, "Hello")
+	}
+	if got := <-pong; string(got) != "are you there" {
+		t.Fatalf("ping answered with %q", got)
+	}
+}
+
+func TestWebSocketRejectsBadFrames(t *testing.T) {
+	mask := [4]byte{9, 8, 7, 6}
+	unmasked := []byte{0x81, 0x05, 'H', 'e', 'l', 'l', 'o'}
+	reserved := clientFrame(true, WebSocketText, mask, []byte("x"))
+	reserved[0] |= 0x40
+	cases := []struct {
+		name   string
+		frames [][]byte
+		want   error
+	}{
+		{"unmasked", [][]byte{unmasked}, ErrWebSocketProtocol},
+		{"reserved bits", [][]byte{reserved}, ErrWebSocketProtocol},
+		{"continuation first", [][]byte{clientFrame(true, wsContinuation, mask, []byte("x"))}, ErrWebSocketProtocol},
+		{"fragmented ping", [][]byte{clientFrame(false, WebSocketPing, mask, nil)}, ErrWebSocketProtocol},
+		{"long control frame", [][]byte{clientFrame(true, WebSocketPing, mask, make([]byte, 126))}, ErrWebSocketProtocol},
+		{"unknown opcode", [][]byte{clientFrame(true, 0x3, mask, nil)}, ErrWebSocketProtocol},
+		{"too large", [][]byte{clientFrame(true, WebSocketText, mask, make([]byte, MaxWebSocketMessage+1))}, ErrWebSocketTooLarge},
+		{"too large in fragments", [][]byte{
+			clientFrame(false, WebSocketText, mask, make([]byte, MaxWebSocketMessage)),
+			clientFrame(true, wsContinuation, mask, []byte("x")),
+		}, ErrWebSocketTooLarge},
+	}
+	for _, tc := range cases {
+		ws, client := pipeWebSocket(t)
+		send(client, tc.frames...)
+		if _, _, err := ws.ReadMessage(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestWebSocketEchoesCloseFrames(t *testing.T) {
+	ws, client := pipeWebSocket(t)
+	send(client, clientFrame(true, WebSocketClose, [4]byte{5, 6, 7, 8}, []byte{0x03, 0xE9, 'b', 'y', 'e'}))
+	reply := make(chan []byte, 1)
+	go func() {
+		opcode, payload := readServerFrame(t, client)
+		if opcode != WebSocketClose {
+			payload = nil
+		}
+		reply <- payload
+	}()
+
+	if _, _, err := ws.ReadMessage(); err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+	if got := <-reply; !bytes.Equal(got, []byte{0x03, 0xE9}) {
+		t.Fatalf("close answered with %v, want the status code 1001 only", got)
+	}
+	if err := ws.WriteMessage(WebSocketText, []byte("late")); err != ErrWebSocketClosed {
+		t.Fatalf("write after close: got %v", err)
+	}
+}
+
+func TestWebSocketWriteFraming(t *testing.T) {
+	cases := []struct {
+		size   int
+		header []byte
+	}{
+		{0, []byte{0x81, 0}},
+		{125, []byte{0x81, 125}},
+		{126, []byte{0x81, 126, 0, 126}},
+		{0xFFFF, []byte{0x81, 126, 0xFF, 0xFF}},
+		{0x10000, []byte{0x81, 127, 0, 0, 0, 0, 0, 1, 0, 0}},
+	}
+	for _, tc := range cases {
+		ws, client := pipeWebSocket(t)
+		payload := bytes.Repeat([]byte{'z'}, tc.size)
+		written := make(chan []byte, 1)
+		go func() {
+			frame := make([]byte, len(tc.header)+tc.size)
+			io.ReadFull(client, frame)
+			written <- frame
+		}()
+
+		if err := ws.WriteMessage(WebSocketText, payload); err != nil {
+			t.Fatalf("%d bytes: %v", tc.size, err)
+		}
+		frame := <-written
+		if !bytes.Equal(frame[:len(tc.header)], tc.header) {
+			t.Errorf("%d bytes: header % x, want % x", tc.size, frame[:len(tc.header)], tc.header)
+		}
+		if !bytes.Equal(frame[len(tc.header):], payload) {
+			t.Errorf("%d bytes: payload was not written as is", tc.size)
+		}
+	}
+}
+
+func TestWebSocketUpgrade(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := NewWebSocketUpgrader().Upgrade(w, r)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotWebSocket) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			}
+			return
+		}
+		defer ws.Close()
+		data, err := ws.ReadMessage()
+		if err == nil {
+			ws.WriteText(bytes.ToUpper(data))
+		}
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("plain request: got status %d, want 400", resp.StatusCode)
+	}
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	// the handshake example of RFC 6455 section 1.3
+	io.WriteString(conn, "GET /live HTTP/1.1\r\n"+
+		"Host: example.com\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("got status %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept is %q", got)
+	}
+
+	conn.Write(clientFrame(true, WebSocketText, [4]byte{0x11, 0x22, 0x33, 0x44}, []byte("shout")))
+	opcode, payload := readServerFrame(t, reader)
+	if opcode != WebSocketText || string(payload) != "SHOUT" {
+		t.Fatalf("got opcode %#x %q, want text %q", opcode, payload, "SHOUT")
+	}
+	if opcode, payload := readServerFrame(t, reader); opcode != WebSocketClose || !bytes.Equal(payload, []byte{0x03, 0xE8}) {
+		t.Fatalf("got opcode %#x % x, want a normal closure", opcode, payload)
+	}
+}
@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoAdminStatsRepository aggregates across the user and blog databases
+type MongoAdminStatsRepository struct {
+	users        *mongo.Collection
+	blogs        *mongo.Collection
+	interactions *mongo.Collection
+	comments     *mongo.Collection
+	stats        *mongo.Collection
+	aiUsage      *mongo.Collection
+}
+
+func NewMongoAdminStatsRepository(users *mongo.Collection, blogDB *mongo.Database) *MongoAdminStatsRepository {
+	return &MongoAdminStatsRepository{
+		users:        users,
+		blogs:        blogDB.Collection("blogs"),
+		interactions: blogDB.Collection("blog_interactions"),
+		comments:     blogDB.Collection("comments"),
+		stats:        blogDB.Collection("blog_stats"),
+		aiUsage:      blogDB.Collection("ai_usage"),
+	}
+}
+
+// utcDay truncates a date expression to midnight UTC inside an aggregation
+func utcDay(expr any) bson.M {
+	return bson.M{"$dateFromParts": bson.M{
+		"year":  bson.M{"$year": expr},
+		"month": bson.M{"$month": expr},
+		"day":   bson.M{"$dayOfMonth": expr},
+	}}
+}
+
+// dailyCounts groups the matching documents by the UTC day of a date expression
+func dailyCounts(ctx context.Context, col *mongo.Collection, match bson.M, dateExpr any) ([]domain.DailyCount, error) {
+	cursor, err := col.Aggregate(ctx, bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{"_id": utcDay(dateExpr), "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	days := []domain.DailyCount{}
+	for cursor.Next(ctx) {
+		var day domain.DailyCount
+		if err := cursor.Decode(&day); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// CountSignups uses the creation time embedded in each user's ObjectID, as users have no created_at
+func (r *MongoAdminStatsRepository) CountSignups(since time.Time) (int, []domain.DailyCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	total, err := r.users.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return 0, nil, err
+	}
+	daily, err := dailyCounts(ctx, r.users, bson.M{"_id": bson.M{"$gte": primitive.NewObjectIDFromTimestamp(since)}}, bson.M{"$toDate": "$_id"})
+	return int(total), daily, err
+}
+
+func (r *MongoAdminStatsRepository) CountAuthors(since time.Time) (int, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	all, err := r.blogs.Distinct(ctx, "user_id", bson.M{})
+	if err != nil {
+		return 0, 0, err
+	}
+	active, err := r.blogs.Distinct(ctx, "user_id", bson.M{"date_created": bson.M{"$gte": since}})
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(all), len(active), nil
+}
+
+func (r *MongoAdminStatsRepository) CountPosts(since time.Time) (int, []domain.DailyCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	total, err := r.blogs.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return 0, nil, err
+	}
+	daily, err := dailyCounts(ctx, r.blogs, bson.M{"date_created": bson.M{"$gte": since}}, "$date_created")
+	return int(total), daily, err
+}
+
+// TopTags counts tags on posts created within the range
+func (r *MongoAdminStatsRepository) TopTags(since time.Time, limit int) ([]domain.FacetCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	cursor, err := r.blogs.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"date_created": bson.M{"$gte": since}}},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tags := []domain.FacetCount{}
+	for cursor.Next(ctx) {
+		var row struct {
+			Tag   string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		tags = append(tags, domain.FacetCount{Value: row.Tag, Count: row.Count})
+	}
+	return tags, nil
+}
+
+// InteractionVolume merges daily views from the analytics buckets, likes and dislikes
+// from the interaction log and comments from the comment collection
+func (r *MongoAdminStatsRepository) InteractionVolume(since time.Time) ([]*domain.StatsBucket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	days := map[time.Time]*domain.StatsBucket{}
+	bucket := func(day time.Time) *domain.StatsBucket {
+		day = day.UTC()
+		b, ok := days[day]
+		if !ok {
+			b = &domain.StatsBucket{Granularity: domain.GranularityDay, Start: day}
+			days[day] = b
+		}
+		return b
+	}
+
+	// views
+	cursor, err := r.stats.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"granularity": domain.GranularityDay, "start": bson.M{"$gte": since}}},
+		bson.M{"$group": bson.M{"_id": "$start", "views": bson.M{"$sum": "$views"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			Day   time.Time `bson:"_id"`
+			Views int       `bson:"views"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			cursor.Close(ctx)
+			return nil, err
+		}
+		bucket(row.Day).Views = row.Views
+	}
+	cursor.Close(ctx)
+
+	// likes and dislikes
+	cursor, err = r.interactions.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"created_at": bson.M{"$gte": since}}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"day": utcDay("$created_at"), "action": "$action"},
+			"count": bson.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			Key struct {
+				Day    time.Time `bson:"day"`
+				Action string    `bson:"action"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			cursor.Close(ctx)
+			return nil, err
+		}
+		switch row.Key.Action {
+		case domain.ReactionLike:
+			bucket(row.Key.Day).Likes = row.Count
+		case domain.ReactionDislike:
+			bucket(row.Key.Day).Dislikes = row.Count
+		}
+	}
+	cursor.Close(ctx)
+
+	// comments
+	comments, err := dailyCounts(ctx, r.comments, bson.M{"created_at": bson.M{"$gte": since}}, "$created_at")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		bucket(c.Day).Comments = c.Count
+	}
+
+	out := make([]*domain.StatsBucket, 0, len(days))
+	for _, b := range days {
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func (r *MongoAdminStatsRepository) RecordAISuggestion(userID primitive.ObjectID, failed bool, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.aiUsage.InsertOne(ctx, bson.M{"user_id": userID, "failed": failed, "created_at": at})
+	return err
+}
+
+func (r *MongoAdminStatsRepository) AISuggestionUsage(since time.Time) (int, int, []domain.DailyCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	match := bson.M{"created_at": bson.M{"$gte": since}}
+	daily, err := dailyCounts(ctx, r.aiUsage, match, "$created_at")
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	total := 0
+	for _, d := range daily {
+		total += d.Count
+	}
+	failed, err := r.aiUsage.CountDocuments(ctx, bson.M{"created_at": bson.M{"$gte": since}, "failed": true})
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return total, int(failed), daily, nil
+}
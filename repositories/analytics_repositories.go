@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoAnalyticsRepository keeps one counter document per blog and bucket in blog_stats,
+// and one per blog, day and referrer or country in blog_stat_breakdowns
+type MongoAnalyticsRepository struct {
+	col           *mongo.Collection
+	breakdownColl *mongo.Collection
+}
+
+func NewMongoAnalyticsRepository(col *mongo.Collection) *MongoAnalyticsRepository {
+	return &MongoAnalyticsRepository{col: col, breakdownColl: col.Database().Collection("blog_stat_breakdowns")}
+}
+
+// EnsureIndexes creates the unique keys the counter upserts rely on
+func (r *MongoAnalyticsRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "granularity", Value: 1}, {Key: "start", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.breakdownColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "dimension", Value: 1}, {Key: "day", Value: 1}, {Key: "value", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// AddStats upserts every counter with $inc in two unordered bulk writes
+func (r *MongoAnalyticsRepository) AddStats(buckets []*domain.StatsBucket, breakdowns []*domain.BreakdownCount) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if len(buckets) > 0 {
+		models := make([]mongo.WriteModel, 0, len(buckets))
+		for _, b := range buckets {
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"blog_id": b.BlogID, "granularity": b.Granularity, "start": b.Start}).
+				SetUpdate(bson.M{"$inc": bson.M{
+					"views":    b.Views,
+					"likes":    b.Likes,
+					"dislikes": b.Dislikes,
+					"comments": b.Comments,
+				}}).
+				SetUpsert(true))
+		}
+		if _, err := r.col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+
+	if len(breakdowns) > 0 {
+		models := make([]mongo.WriteModel, 0, len(breakdowns))
+		for _, b := range breakdowns {
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"blog_id": b.BlogID, "dimension": b.Dimension, "day": b.Day, "value": b.Value}).
+				SetUpdate(bson.M{"$inc": bson.M{"count": b.Count}}).
+				SetUpsert(true))
+		}
+		if _, err := r.breakdownColl.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBuckets returns the stored buckets starting within [from, to), oldest first
+func (r *MongoAnalyticsRepository) GetBuckets(blogID primitive.ObjectID, granularity string, from, to time.Time) ([]*domain.StatsBucket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"blog_id":     blogID,
+		"granularity": granularity,
+		"start":       bson.M{"$gte": from, "$lt": to},
+	}
+	cursor, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "start", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []*domain.StatsBucket{}
+	for cursor.Next(ctx) {
+		var b domain.StatsBucket
+		if err := cursor.Decode(&b); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, &b)
+	}
+	return buckets, nil
+}
+
+// GetBreakdown sums a dimension's daily counters over [from, to), largest first
+func (r *MongoAnalyticsRepository) GetBreakdown(blogID primitive.ObjectID, dimension string, from, to time.Time, limit int) ([]domain.FacetCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.breakdownColl.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{
+			"blog_id":   blogID,
+			"dimension": dimension,
+			"day":       bson.M{"$gte": from, "$lt": to},
+		}},
+		bson.M{"$group": bson.M{"_id": "$value", "count": bson.M{"$sum": "$count"}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	counts := []domain.FacetCount{}
+	for cursor.Next(ctx) {
+		var row struct {
+			Value string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		counts = append(counts, domain.FacetCount{Value: row.Value, Count: row.Count})
+	}
+	return counts, nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// fields searched by the free-text condition of a filter
+var filterTextFields = []string{"title", "content", "tags", "author_username"}
+
+// compileBlogFilter translates a validated filter tree into a Mongo query
+func compileBlogFilter(f *domain.BlogFilter) bson.M {
+	if f == nil {
+		return bson.M{}
+	}
+
+	var clauses []bson.M
+
+	if f.Tags != nil {
+		if len(f.Tags.Any) > 0 {
+			clauses = append(clauses, bson.M{"tags": bson.M{"$in": f.Tags.Any}})
+		}
+		if len(f.Tags.All) > 0 {
+			clauses = append(clauses, bson.M{"tags": bson.M{"$all": f.Tags.All}})
+		}
+		if len(f.Tags.None) > 0 {
+			clauses = append(clauses, bson.M{"tags": bson.M{"$nin": f.Tags.None}})
+		}
+	}
+	if len(f.Authors) > 0 {
+		clauses = append(clauses, bson.M{"user_id": bson.M{"$in": f.Authors}})
+	}
+	if f.MinLikes != nil {
+		clauses = append(clauses, bson.M{"likes": bson.M{"$gte": *f.MinLikes}})
+	}
+	if f.MinViews != nil {
+		clauses = append(clauses, bson.M{"view_count": bson.M{"$gte": *f.MinViews}})
+	}
+	switch f.Status {
+	case domain.BlogStatusPublished:
+		clauses = append(clauses, visibleFilter())
+	case domain.BlogStatusHidden:
+		clauses = append(clauses, bson.M{"status": domain.BlogStatusHidden})
+	}
+	if f.From != nil || f.To != nil {
+		rangeQuery := bson.M{}
+		if f.From != nil {
+			rangeQuery["$gte"] = *f.From
+		}
+		if f.To != nil {
+			rangeQuery["$lte"] = *f.To
+		}
+		clauses = append(clauses, bson.M{"date_created": rangeQuery})
+	}
+	// every word of the text must appear in one of the text fields
+	for _, word := range strings.FieldsFunc(f.Text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		pattern := regexp.QuoteMeta(word)
+		or := make([]bson.M, 0, len(filterTextFields))
+		for _, field := range filterTextFields {
+			or = append(or, bson.M{field: bson.M{"$regex": pattern, "$options": "i"}})
+		}
+		clauses = append(clauses, bson.M{"$or": or})
+	}
+
+	for _, child := range f.And {
+		clauses = append(clauses, compileBlogFilter(child))
+	}
+	if len(f.Or) > 0 {
+		or := make([]bson.M, 0, len(f.Or))
+		for _, child := range f.Or {
+			or = append(or, compileBlogFilter(child))
+		}
+		clauses = append(clauses, bson.M{"$or": or})
+	}
+	if f.Not != nil {
+		clauses = append(clauses, bson.M{"$nor": []bson.M{compileBlogFilter(f.Not)}})
+	}
+
+	switch len(clauses) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return clauses[0]
+	}
+	return bson.M{"$and": clauses}
+}
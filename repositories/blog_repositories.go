@@ -4,6 +4,7 @@ import (
 	"Blog/domain"
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,15 +23,59 @@ func NewMongoBlogRepository(col mongo.Collection) *MongoBlogRepository {
 }
 
 
-// IncrementViewCount increases view count atomically
-func (r *MongoBlogRepository) IncrementViewCount(blogID primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// AddViewCounts applies a batch of buffered view increments in one round trip
+func (r *MongoBlogRepository) AddViewCounts(counts map[primitive.ObjectID]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	_, err := r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$inc": bson.M{"view_count": 1}})
+	models := make([]mongo.WriteModel, 0, len(counts))
+	for id, n := range counts {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$inc": bson.M{"view_count": n}}))
+	}
+	_, err := r.col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
 	return err
 }
 
+// SetCommentMode changes whether a blog accepts comments and whether they need approval
+func (r *MongoBlogRepository) SetCommentMode(blogID primitive.ObjectID, mode string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$set": bson.M{"comment_mode": mode}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("blog not found")
+	}
+	return nil
+}
+
+// SetBlogStatus hides or republishes a blog
+func (r *MongoBlogRepository) SetBlogStatus(blogID primitive.ObjectID, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$set": bson.M{"status": status}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("blog not found")
+	}
+	return nil
+}
+
+// visibleFilter matches blogs readers may see; documents created before statuses existed count as published
+func visibleFilter() bson.M {
+	return bson.M{"status": bson.M{"$ne": domain.BlogStatusHidden}}
+}
+
 // create a task
 func (r *MongoBlogRepository) CreateBlog(blog *domain.Blog) (*domain.Blog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -53,53 +98,58 @@ func (r *MongoBlogRepository) GetBlogByID(id primitive.ObjectID) (*domain.Blog,
 		var blog domain.Blog
 		err := r.col.FindOne(ctx, bson.M{"_id" :id}).Decode(&blog)
 
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrBlogNotFound
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		r.IncrementViewCount(id)
-
 		return &blog, nil
 }
 	
-// retrive all blogs
-func (r *MongoBlogRepository) GetAllBlogs() ([]*domain.Blog, error) {
+// retrive all blogs, newest first, one page at a time
+func (r *MongoBlogRepository) GetAllBlogs(page domain.PageRequest) (*domain.Page[*domain.Blog], error) {
+	return r.findBlogPage(visibleFilter(), "date", page)
+}
+
+// update a blog using its id; only the fields set in update are written
+func (r *MongoBlogRepository) UpdateBlog(id primitive.ObjectID, update domain.BlogUpdate) (*domain.Blog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cursor, err := r.col.Find(ctx, bson.M{})
-
-	if err != nil {
-		return nil, err
+	set := bson.M{"date_updated": time.Now()}
+	if update.Title != nil {
+		set["title"] = *update.Title
 	}
-	defer cursor.Close(ctx)
-
-	var blogs []*domain.Blog
-	for cursor.Next(ctx) {
-		var blog domain.Blog
-		if err := cursor.Decode(&blog); err != nil {
-			return nil, err
+	if update.Content != nil {
+		set["content"] = *update.Content
+	}
+	if update.Tags != nil {
+		tags := *update.Tags
+		if tags == nil {
+			tags = []string{}
 		}
-		blogs = append(blogs, &blog)
-
+		set["tags"] = tags
+	}
+	if update.MediaIDs != nil {
+		ids := *update.MediaIDs
+		if ids == nil {
+			ids = []primitive.ObjectID{}
+		}
+		set["media_ids"] = ids
 	}
 
-	return blogs, nil
-}
-
-// update a blog using its id
-func (r *MongoBlogRepository) UpdateBlog(id primitive.ObjectID, blog *domain.Blog) (*domain.Blog, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	_, err := r.col.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": blog})
+	var blog domain.Blog
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.col.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set}, opts).Decode(&blog)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.New("blog not found")
+	}
 	if err != nil {
 		return nil, err
 	}
-
-	blog.ID = id // ensure the id is set in the returned blog
-	
-	return blog, nil
+	return &blog, nil
 }
 
 // delete a blog using its id
@@ -130,148 +180,647 @@ func (r *MongoBlogRepository) findInteraction(ctx context.Context, userID, blogI
 	return &bi, nil
 }
 
-// LikeBlog implements logic to prevent duplicate likes and handle switch from dislike to like
-func (r *MongoBlogRepository) LikeBlog(userID, blogID primitive.ObjectID) error {
+// reactionInc adds a reaction counter change to an $inc document; likes and dislikes also
+// keep their own counters, which sorting, filtering and trending rely on
+func reactionInc(inc bson.M, reaction string, delta int) {
+	inc["reactions."+reaction] = delta
+	switch reaction {
+	case domain.ReactionLike:
+		inc["likes"] = delta
+	case domain.ReactionDislike:
+		inc["dislikes"] = delta
+	}
+}
+
+// EnsureInteractionIndex enforces one reaction per user and blog. Duplicates left by the
+// old non-atomic like/dislike writes are removed first, keeping each user's latest reaction.
+func (r *MongoBlogRepository) EnsureInteractionIndex() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cursor, err := r.interactColl.Aggregate(ctx, bson.A{
+		bson.M{"$sort": bson.M{"created_at": -1}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"user": "$user_id", "blog": "$blog_id"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	var stale []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var row struct {
+			IDs []primitive.ObjectID `bson:"ids"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			cursor.Close(ctx)
+			return err
+		}
+		stale = append(stale, row.IDs[1:]...)
+	}
+	cursor.Close(ctx)
+	if len(stale) > 0 {
+		if _, err := r.interactColl.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": stale}}); err != nil {
+			return err
+		}
+	}
+
+	_, err = r.interactColl.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "blog_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("one_reaction_per_user"),
+	})
+	return err
+}
+
+// SetReaction records the user's reaction, replacing any earlier one, and returns the previous
+// reaction. The interaction is switched with a single upsert on the unique (user_id, blog_id)
+// index, so concurrent requests see each other's result and counters move exactly once.
+func (r *MongoBlogRepository) SetReaction(userID, blogID primitive.ObjectID, reaction string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// check if blog exists
-	var blog domain.Blog
-	if err := r.col.FindOne(ctx, bson.M{"_id": blogID}).Decode(&blog); err != nil {
-		return errors.New("blog not found")
+	if err := r.col.FindOne(ctx, bson.M{"_id": blogID}).Err(); err != nil {
+		return "", errors.New("blog not found")
+	}
+
+	// a pipeline update so repeating the current reaction keeps its original created_at
+	filter := bson.M{"user_id": userID, "blog_id": blogID}
+	update := bson.A{bson.M{"$set": bson.M{
+		"created_at": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$action", reaction}}, "$created_at", time.Now()}},
+		"action":     reaction,
+	}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var prev domain.BlogInteraction
+	err := r.interactColl.FindOneAndUpdate(ctx, filter, update, opts).Decode(&prev)
+	switch {
+	case err == mongo.ErrNoDocuments:
+		// first reaction by this user, nothing to undo
+	case mongo.IsDuplicateKeyError(err):
+		// a concurrent first reaction won the insert; ours replaces it
+		err = r.interactColl.FindOneAndUpdate(ctx, filter, update, opts).Decode(&prev)
+		if err != nil {
+			return "", err
+		}
+	case err != nil:
+		return "", err
+	}
+	if prev.Action == reaction {
+		return reaction, nil
+	}
+
+	inc := bson.M{}
+	if prev.Action != "" {
+		reactionInc(inc, prev.Action, -1)
+	}
+	reactionInc(inc, reaction, 1)
+	if _, err := r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$inc": inc}); err != nil {
+		return "", err
 	}
+	return prev.Action, nil
+}
 
-	// check previous interaction
-	prev, err := r.findInteraction(ctx, userID, blogID)
+// RemoveReaction withdraws the user's reaction if it is the given one
+func (r *MongoBlogRepository) RemoveReaction(userID, blogID primitive.ObjectID, reaction string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// deleting by the full key makes concurrent removals count once
+	res, err := r.interactColl.DeleteOne(ctx, bson.M{"user_id": userID, "blog_id": blogID, "action": reaction})
 	if err != nil {
 		return err
 	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNoReaction
+	}
 
-	if prev != nil && prev.Action == "like" {
-		return errors.New("user already liked this blog")
+	inc := bson.M{}
+	reactionInc(inc, reaction, -1)
+	_, err = r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$inc": inc})
+	return err
+}
+
+// ReconcileReactions recomputes every blog's reaction, like and dislike counters from the
+// interaction log and corrects the ones that drifted. It also fills the counters of blogs
+// created before reactions existed. Returns how many blogs were corrected.
+func (r *MongoBlogRepository) ReconcileReactions() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	cursor, err := r.col.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"likes": 1, "dislikes": 1, "reactions": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	type counters struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Likes     int                `bson:"likes"`
+		Dislikes  int                `bson:"dislikes"`
+		Reactions map[string]int     `bson:"reactions"`
 	}
 
-	// start a session-less update sequence
-	if prev != nil && prev.Action == "dislike" {
-		// remove dislike record and decrement dislike counter
-		_, err := r.interactColl.DeleteOne(ctx, bson.M{"_id": prev.ID})
+	fixed := 0
+	var batch []counters
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		ids := make([]primitive.ObjectID, len(batch))
+		actual := make(map[primitive.ObjectID]map[string]int, len(batch))
+		for i, b := range batch {
+			ids[i] = b.ID
+			actual[b.ID] = map[string]int{}
+		}
+
+		agg, err := r.interactColl.Aggregate(ctx, bson.A{
+			bson.M{"$match": bson.M{"blog_id": bson.M{"$in": ids}}},
+			bson.M{"$group": bson.M{"_id": bson.M{"blog": "$blog_id", "action": "$action"}, "count": bson.M{"$sum": 1}}},
+		})
 		if err != nil {
 			return err
 		}
-		_, err = r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$inc": bson.M{"dislikes": -1}})
+		for agg.Next(ctx) {
+			var row struct {
+				Key struct {
+					BlogID primitive.ObjectID `bson:"blog"`
+					Action string             `bson:"action"`
+				} `bson:"_id"`
+				Count int `bson:"count"`
+			}
+			if err := agg.Decode(&row); err != nil {
+				agg.Close(ctx)
+				return err
+			}
+			actual[row.Key.BlogID][row.Key.Action] = row.Count
+		}
+		agg.Close(ctx)
+
+		var models []mongo.WriteModel
+		for _, b := range batch {
+			want := actual[b.ID]
+			if b.Reactions != nil && b.Likes == want[domain.ReactionLike] && b.Dislikes == want[domain.ReactionDislike] && sameCounts(b.Reactions, want) {
+				continue
+			}
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": b.ID}).
+				SetUpdate(bson.M{"$set": bson.M{
+					"reactions": want,
+					"likes":     want[domain.ReactionLike],
+					"dislikes":  want[domain.ReactionDislike],
+				}}))
+		}
+		batch = batch[:0]
+		if len(models) == 0 {
+			return nil
+		}
+		res, err := r.col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
 		if err != nil {
 			return err
 		}
+		fixed += int(res.ModifiedCount)
+		return nil
 	}
 
-	// insert like interaction
-	bi := domain.BlogInteraction{
-		ID:        primitive.NewObjectID(),
-		BlogID:    blogID,
-		UserID:    userID,
-		Action:    "like",
-		CreatedAt: time.Now(),
+	for cursor.Next(ctx) {
+		var c counters
+		if err := cursor.Decode(&c); err != nil {
+			return fixed, err
+		}
+		batch = append(batch, c)
+		if len(batch) == 500 {
+			if err := flush(); err != nil {
+				return fixed, err
+			}
+		}
 	}
-	if _, err := r.interactColl.InsertOne(ctx, bi); err != nil {
-		return err
+	if err := flush(); err != nil {
+		return fixed, err
 	}
+	return fixed, nil
+}
+
+// sameCounts compares counters, treating missing and zero entries alike
+func sameCounts(a, b map[string]int) bool {
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	for k, v := range b {
+		if a[k] != v {
+			return false
+		}
+	}
+	return true
+}
 
-	r.IncrementViewCount(blogID)
 
-	// increment likes on blog
-	_, err = r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$inc": bson.M{"likes": 1}})
-	return err
+
+// blogSort is a keyset sort plus how to read its key values off a blog for the next cursor
+type blogSort struct {
+	keys   []sortKey
+	values func(*domain.Blog) bson.A
 }
 
-// DislikeBlog similar to LikeBlog
-func (r *MongoBlogRepository) DislikeBlog(userID, blogID primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// blogSorts are the keyset sorts available to blog listings; each ends on _id so cursors are unambiguous
+var blogSorts = map[string]blogSort{
+	"date": {
+		keys:   []sortKey{{Field: "date_created", Desc: true}, {Field: "_id", Desc: true}},
+		values: func(b *domain.Blog) bson.A { return bson.A{b.DateCreated, b.ID} },
+	},
+	"popularity": {
+		keys:   []sortKey{{Field: "likes", Desc: true}, {Field: "view_count", Desc: true}, {Field: "_id", Desc: true}},
+		values: func(b *domain.Blog) bson.A { return bson.A{b.Likes, b.ViewCount, b.ID} },
+	},
+	"trending":                         trendingSort(domain.TrendingDay),
+	"trending_" + domain.TrendingWeek:  trendingSort(domain.TrendingWeek),
+	"trending_" + domain.TrendingMonth: trendingSort(domain.TrendingMonth),
+}
+
+// trendingSort orders by one window's trending score; blogs the job has not scored yet sort last
+func trendingSort(window string) blogSort {
+	return blogSort{
+		keys: []sortKey{{Field: "trending." + window, Desc: true}, {Field: "_id", Desc: true}},
+		values: func(b *domain.Blog) bson.A {
+			if b.Trending == nil {
+				return bson.A{nil, b.ID}
+			}
+			score := map[string]float64{
+				domain.TrendingDay:   b.Trending.Day,
+				domain.TrendingWeek:  b.Trending.Week,
+				domain.TrendingMonth: b.Trending.Month,
+			}[window]
+			return bson.A{score, b.ID}
+		},
+	}
+}
+
+// number of buckets returned for the tag and author facets
+const facetLimit = 25
+
+// FilterBlogs runs a filter tree with sorting by date or popularity.
+// One $facet aggregation returns the page together with tag, author and month counts
+// computed over the whole filtered set.
+func (r *MongoBlogRepository) FilterBlogs(f *domain.BlogFilter, sortBy string, page domain.PageRequest, includeHidden bool) (*domain.BlogFilterResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var blog domain.Blog
-	if err := r.col.FindOne(ctx, bson.M{"_id": blogID}).Decode(&blog); err != nil {
-		return errors.New("blog not found")
+	filter := compileBlogFilter(f)
+	if !includeHidden {
+		filter = bson.M{"$and": []bson.M{visibleFilter(), filter}}
+	}
+
+	if _, ok := blogSorts[sortBy]; !ok {
+		sortBy = "date"
+	}
+	sort := blogSorts[sortBy]
+	page = page.Normalize()
+
+	items := bson.A{}
+	if page.Cursor != "" {
+		values, err := decodeCursor(page.Cursor, sortBy, sort.keys)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, bson.M{"$match": keysetFilter(sort.keys, values)})
+	}
+	items = append(items,
+		bson.M{"$sort": sortDoc(sort.keys)},
+		bson.M{"$limit": page.Limit + 1},
+	)
+
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$facet": bson.M{
+			"items": items,
+			"tags": bson.A{
+				bson.M{"$unwind": "$tags"},
+				bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": facetLimit},
+				bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "count": 1}},
+			},
+			"authors": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   "$user_id",
+					"label": bson.M{"$first": "$author_username"},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": facetLimit},
+				bson.M{"$project": bson.M{"_id": 0, "value": bson.M{"$toString": "$_id"}, "label": 1, "count": 1}},
+			},
+			"months": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": "$date_created"}},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": -1}},
+				bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "count": 1}},
+			},
+		}},
 	}
 
-	prev, err := r.findInteraction(ctx, userID, blogID)
+	cursor, err := r.col.Aggregate(ctx, pipeline)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	if prev != nil && prev.Action == "dislike" {
-		return errors.New("user already disliked this blog")
+	var out []struct {
+		Items   []*domain.Blog      `bson:"items"`
+		Tags    []domain.FacetCount `bson:"tags"`
+		Authors []domain.FacetCount `bson:"authors"`
+		Months  []domain.FacetCount `bson:"months"`
+	}
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, err
 	}
 
-	if prev != nil && prev.Action == "like" {
-		_, err := r.interactColl.DeleteOne(ctx, bson.M{"_id": prev.ID})
-		if err != nil {
-			return err
+	result := &domain.BlogFilterResult{Facets: domain.BlogFacets{
+		Tags:    []domain.FacetCount{},
+		Authors: []domain.FacetCount{},
+		Months:  []domain.FacetCount{},
+	}}
+	var blogs []*domain.Blog
+	if len(out) > 0 {
+		blogs = out[0].Items
+		if out[0].Tags != nil {
+			result.Facets.Tags = out[0].Tags
 		}
-		_, err = r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$inc": bson.M{"likes": -1}})
-		if err != nil {
-			return err
+		if out[0].Authors != nil {
+			result.Facets.Authors = out[0].Authors
+		}
+		if out[0].Months != nil {
+			result.Facets.Months = out[0].Months
 		}
 	}
+	result.Page = *buildPage(blogs, page, sortBy, sort.values)
+	return result, nil
+}
+
+// helper to run a paginated blog query under one of blogSorts (date when unknown)
+func (r *MongoBlogRepository) findBlogPage(filter bson.M, sortBy string, page domain.PageRequest) (*domain.Page[*domain.Blog], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, ok := blogSorts[sortBy]; !ok {
+		sortBy = "date"
+	}
+	sort := blogSorts[sortBy]
 
-	bi := domain.BlogInteraction{
-		ID:        primitive.NewObjectID(),
-		BlogID:    blogID,
-		UserID:    userID,
-		Action:    "dislike",
-		CreatedAt: time.Now(),
+	query, findOptions, err := pageQuery(filter, sortBy, sort.keys, page)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := r.interactColl.InsertOne(ctx, bi); err != nil {
-		return err
+
+	cursor, err := r.col.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	r.IncrementViewCount(blogID)
+	var blogs []*domain.Blog
+	for cursor.Next(ctx) {
+		var b domain.Blog
+		if err := cursor.Decode(&b); err != nil {
+			return nil, err
+		}
+		blogs = append(blogs, &b)
+	}
 
-	_, err = r.col.UpdateOne(ctx, bson.M{"_id": blogID}, bson.M{"$inc": bson.M{"dislikes": 1}})
+	return buildPage(blogs, page, sortBy, sort.values), nil
+}
+
+// UpdateTrendingScores writes the scores computed by the trending job in one bulk write
+func (r *MongoBlogRepository) UpdateTrendingScores(scores map[primitive.ObjectID]domain.TrendingScores) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(scores))
+	for id, score := range scores {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$set": bson.M{"trending": score}}))
+	}
+	_, err := r.col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
 	return err
 }
 
+// ScanRecentBlogs returns the next visible blogs created since the given time after afterID in _id order, with only their counters
+func (r *MongoBlogRepository) ScanRecentBlogs(since time.Time, afterID primitive.ObjectID, limit int) ([]*domain.Blog, error) {
+	filter := visibleFilter()
+	filter["date_created"] = bson.M{"$gte": since}
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"likes": 1, "dislikes": 1, "view_count": 1, "date_created": 1})
+	return r.findBlogs(filter, opts)
+}
 
+// ResetTrendingScores zeroes missing or nonzero trending scores of posts created before the given time
+func (r *MongoBlogRepository) ResetTrendingScores(before time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-// FilterBlogs supports tags, optional date range, and sorting by date or popularity
-func (r *MongoBlogRepository) FilterBlogs(tags []string, startDate, endDate *time.Time, sortBy string) ([]*domain.Blog, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	res, err := r.col.UpdateMany(ctx,
+		bson.M{
+			"date_created": bson.M{"$lt": before},
+			"$or": bson.A{
+				bson.M{"trending": nil},
+				bson.M{"trending.day": bson.M{"$ne": 0}},
+				bson.M{"trending.week": bson.M{"$ne": 0}},
+				bson.M{"trending.month": bson.M{"$ne": 0}},
+			},
+		},
+		bson.M{"$set": bson.M{"trending": domain.TrendingScores{UpdatedAt: time.Now()}}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(res.ModifiedCount), nil
+}
+
+// GetTrending lists visible blogs created since the given time by one window's trending score
+func (r *MongoBlogRepository) GetTrending(window string, since time.Time, page domain.PageRequest) (*domain.Page[*domain.Blog], error) {
+	sortBy := "trending"
+	if window != domain.TrendingDay {
+		sortBy = "trending_" + window
+	}
+	filter := visibleFilter()
+	filter["date_created"] = bson.M{"$gte": since}
+	// posts created since the last run have no score yet; a null would end keyset paging
+	filter["trending."+window] = bson.M{"$exists": true}
+	return r.findBlogPage(filter, sortBy, page)
+}
+
+// GetBlogsByIDs loads the visible blogs among ids, in no particular order
+func (r *MongoBlogRepository) GetBlogsByIDs(ids []primitive.ObjectID) ([]*domain.Blog, error) {
+	if len(ids) == 0 {
+		return []*domain.Blog{}, nil
+	}
+	filter := visibleFilter()
+	filter["_id"] = bson.M{"$in": ids}
+	return r.findBlogs(filter, options.Find())
+}
+
+// GetFeed returns visible posts by any of the authors or with any of the tags, newest first
+func (r *MongoBlogRepository) GetFeed(q domain.FeedQuery) ([]*domain.Blog, error) {
+	var sources []bson.M
+	if len(q.AuthorIDs) > 0 {
+		sources = append(sources, bson.M{"user_id": bson.M{"$in": q.AuthorIDs}})
+	}
+	if len(q.Tags) > 0 {
+		sources = append(sources, bson.M{"tags": bson.M{"$in": q.Tags}})
+	}
+	if len(sources) == 0 || q.Limit <= 0 {
+		return []*domain.Blog{}, nil
+	}
+
+	clauses := []bson.M{visibleFilter(), {"$or": sources}}
+	if !q.Before.IsZero() {
+		clauses = append(clauses, keysetFilter(blogSorts["date"].keys, bson.A{q.Before, q.BeforeID}))
+	}
+	opts := options.Find().SetSort(sortDoc(blogSorts["date"].keys)).SetLimit(int64(q.Limit))
+	return r.findBlogs(bson.M{"$and": clauses}, opts)
+}
+
+// GetRecentBlogs returns the newest visible posts, optionally filtered by author, tag and creation time
+func (r *MongoBlogRepository) GetRecentBlogs(authorID *primitive.ObjectID, tag string, since time.Time, limit int) ([]*domain.Blog, error) {
+	filter := visibleFilter()
+	if authorID != nil {
+		filter["user_id"] = *authorID
+	}
+	if tag != "" {
+		filter["tags"] = tag
+	}
+	if !since.IsZero() {
+		filter["date_created"] = bson.M{"$gt": since}
+	}
+	opts := options.Find().SetSort(sortDoc(blogSorts["date"].keys)).SetLimit(int64(limit))
+	return r.findBlogs(filter, opts)
+}
+
+// GetBlogsSince returns the oldest visible posts created after since, optionally filtered by author and tag
+func (r *MongoBlogRepository) GetBlogsSince(authorID *primitive.ObjectID, tag string, since time.Time, limit int) ([]*domain.Blog, error) {
+	filter := visibleFilter()
+	filter["date_created"] = bson.M{"$gt": since}
+	if authorID != nil {
+		filter["user_id"] = *authorID
+	}
+	if tag != "" {
+		filter["tags"] = tag
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "date_created", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	return r.findBlogs(filter, opts)
+}
+
+// ScanBlogs returns the next visible blogs after afterID in _id order, with only what listings of every post need
+func (r *MongoBlogRepository) ScanBlogs(afterID primitive.ObjectID, limit int) ([]*domain.Blog, error) {
+	filter := visibleFilter()
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"user_id": 1, "tags": 1, "date_created": 1, "date_updated": 1, "status": 1})
+	return r.findBlogs(filter, opts)
+}
+
+// RemoveMedia pulls a deleted media file from every blog referencing it
+func (r *MongoBlogRepository) RemoveMedia(mediaID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	filter := bson.M{}
-	if len(tags) > 0 {
-		filter["tags"] = bson.M{"$all": tags}
+	_, err := r.col.UpdateMany(ctx, bson.M{"media_ids": mediaID}, bson.M{"$pull": bson.M{"media_ids": mediaID}})
+	return err
+}
+
+// FindBlogsByTags returns visible blogs sharing at least one tag, most liked first
+func (r *MongoBlogRepository) FindBlogsByTags(tags []string, excludeID primitive.ObjectID, limit int) ([]*domain.Blog, error) {
+	if len(tags) == 0 {
+		return []*domain.Blog{}, nil
 	}
+	filter := visibleFilter()
+	filter["tags"] = bson.M{"$in": tags}
+	filter["_id"] = bson.M{"$ne": excludeID}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "likes", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	return r.findBlogs(filter, opts)
+}
+
+// GetCoLikedBlogs finds what readers who liked a blog also liked, with how many of them did
+func (r *MongoBlogRepository) GetCoLikedBlogs(blogID primitive.ObjectID, limit int) (map[primitive.ObjectID]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.interactColl.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"blog_id": blogID, "action": domain.ReactionLike}},
+		// cap the readers considered so very popular posts stay cheap
+		bson.M{"$sort": bson.M{"created_at": -1}},
+		bson.M{"$limit": 500},
+		bson.M{"$lookup": bson.M{
+			"from": r.interactColl.Name(),
+			"let":  bson.M{"uid": "$user_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$user_id", "$$uid"}},
+					bson.M{"$eq": bson.A{"$action", domain.ReactionLike}},
+					bson.M{"$ne": bson.A{"$blog_id", blogID}},
+				}}}},
+				bson.M{"$project": bson.M{"blog_id": 1}},
+			},
+			"as": "other",
+		}},
+		bson.M{"$unwind": "$other"},
+		bson.M{"$group": bson.M{"_id": "$other.blog_id", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: -1}}},
+		bson.M{"$limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
 
-	if startDate != nil || endDate != nil {
-		rangeQuery := bson.M{}
-		if startDate != nil {
-			rangeQuery["$gte"] = *startDate
+	counts := map[primitive.ObjectID]int{}
+	for cursor.Next(ctx) {
+		var row struct {
+			BlogID primitive.ObjectID `bson:"_id"`
+			Count  int                `bson:"count"`
 		}
-		if endDate != nil {
-			rangeQuery["$lte"] = *endDate
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
 		}
-		filter["date_created"] = rangeQuery
+		counts[row.BlogID] = row.Count
 	}
+	return counts, nil
+}
 
-	findOptions := options.Find()
-	// sort
-	switch sortBy {
-	case "popularity":
-		// popularity: likes + view_count (descending)
-		findOptions.SetSort(bson.D{{"likes", -1}, {"view_count", -1}, {"date_created", -1}})
-	case "date":
-		findOptions.SetSort(bson.D{{"date_created", -1}})
-	default:
-		findOptions.SetSort(bson.D{{"date_created", -1}})
-	}
+// helper to run a find and decode every blog
+func (r *MongoBlogRepository) findBlogs(filter bson.M, opts *options.FindOptions) ([]*domain.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	cursor, err := r.col.Find(ctx, filter, findOptions)
+	cursor, err := r.col.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var blogs []*domain.Blog
+	blogs := []*domain.Blog{}
 	for cursor.Next(ctx) {
 		var b domain.Blog
 		if err := cursor.Decode(&b); err != nil {
@@ -279,25 +828,114 @@ func (r *MongoBlogRepository) FilterBlogs(tags []string, startDate, endDate *tim
 		}
 		blogs = append(blogs, &b)
 	}
-
 	return blogs, nil
 }
 
-func (r *MongoBlogRepository) SearchBlog(title string) (*domain.Blog, error) {
-    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-    defer cancel()
+// name of the weighted text index backing SearchBlogs
+const blogTextIndex = "blog_text_search"
+
+// relevance cursors are keyed on the text score and _id
+var relevanceSortKeys = []sortKey{{Field: "score", Desc: true}, {Field: "_id", Desc: true}}
+
+// EnsureSearchIndex creates the weighted text index used by SearchBlogs
+func (r *MongoBlogRepository) EnsureSearchIndex() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "content", Value: "text"},
+			{Key: "tags", Value: "text"},
+			{Key: "author_username", Value: "text"},
+		},
+		Options: options.Index().
+			SetName(blogTextIndex).
+			SetWeights(bson.D{
+				{Key: "title", Value: 10},
+				{Key: "tags", Value: 5},
+				{Key: "author_username", Value: 3},
+				{Key: "content", Value: 1},
+			}),
+	})
+	return err
+}
+
+// SearchBlogs runs a $text query and returns hits ordered by text score
+func (r *MongoBlogRepository) SearchBlogs(terms []string, page domain.PageRequest) (*domain.Page[*domain.SearchHit], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-    filter := bson.M{
-        "title": bson.M{
-            "$regex":   title,
-            "$options": "i", // case-insensitive
-        },
-    }
+	page = page.Normalize()
+	search := strings.Join(terms, " ")
+	// cursors only make sense for the query that produced them
+	sortName := "relevance:" + search
 
-    var blog domain.Blog
-    err := r.col.FindOne(ctx, filter).Decode(&blog)
-    if err != nil {
-        return nil, err
-    }
-    return &blog, nil
+	match := visibleFilter()
+	match["$text"] = bson.M{"$search": search}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if page.Cursor != "" {
+		values, err := decodeCursor(page.Cursor, sortName, relevanceSortKeys)
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: keysetFilter(relevanceSortKeys, values)}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: sortDoc(relevanceSortKeys)}},
+		bson.D{{Key: "$limit", Value: page.Limit + 1}},
+	)
+
+	cursor, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var hits []*domain.SearchHit
+	for cursor.Next(ctx) {
+		var doc struct {
+			domain.Blog `bson:",inline"`
+			Score       float64 `bson:"score"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		blog := doc.Blog
+		hits = append(hits, &domain.SearchHit{Blog: &blog, Score: doc.Score})
+	}
+
+	return buildPage(hits, page, sortName, func(h *domain.SearchHit) bson.A { return bson.A{h.Score, h.Blog.ID} }), nil
+}
+
+// GetAuthorsMissingName lists authors whose blogs predate the denormalized author_username
+func (r *MongoBlogRepository) GetAuthorsMissingName() ([]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	values, err := r.col.Distinct(ctx, "user_id", bson.M{"author_username": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// SetAuthorName stores the author's username on all of their blogs
+func (r *MongoBlogRepository) SetAuthorName(userID primitive.ObjectID, username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.UpdateMany(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{"author_username": username}})
+	return err
 }
@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoBookmarkRepository struct {
+	col       *mongo.Collection
+	listsColl *mongo.Collection
+}
+
+func NewMongoBookmarkRepository(col *mongo.Collection) *MongoBookmarkRepository {
+	return &MongoBookmarkRepository{col: col, listsColl: col.Database().Collection("reading_lists")}
+}
+
+// bookmarks are listed newest first
+var bookmarkSortKeys = []sortKey{{Field: "created_at", Desc: true}, {Field: "_id", Desc: true}}
+
+func (r *MongoBookmarkRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "blog_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "blog_id", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.listsColl.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "blog_ids", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoBookmarkRepository) AddBookmark(userID, blogID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.col.UpdateOne(ctx,
+		bson.M{"user_id": userID, "blog_id": blogID},
+		bson.M{"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "created_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent request saved it first
+		return nil
+	}
+	return err
+}
+
+func (r *MongoBookmarkRepository) RemoveBookmark(userID, blogID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.col.DeleteOne(ctx, bson.M{"user_id": userID, "blog_id": blogID})
+	return err
+}
+
+func (r *MongoBookmarkRepository) GetBookmarks(userID primitive.ObjectID, page domain.PageRequest) (*domain.Page[*domain.Bookmark], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query, findOptions, err := pageQuery(bson.M{"user_id": userID}, "bookmarks", bookmarkSortKeys, page)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := r.col.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bookmarks := []*domain.Bookmark{}
+	for cursor.Next(ctx) {
+		var b domain.Bookmark
+		if err := cursor.Decode(&b); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, &b)
+	}
+	return buildPage(bookmarks, page, "bookmarks", func(b *domain.Bookmark) bson.A { return bson.A{b.CreatedAt, b.ID} }), nil
+}
+
+func (r *MongoBookmarkRepository) BookmarkedAmong(userID primitive.ObjectID, blogIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	marked := map[primitive.ObjectID]bool{}
+	if len(blogIDs) == 0 {
+		return marked, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Find(ctx,
+		bson.M{"user_id": userID, "blog_id": bson.M{"$in": blogIDs}},
+		options.Find().SetProjection(bson.M{"blog_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var row struct {
+			BlogID primitive.ObjectID `bson:"blog_id"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		marked[row.BlogID] = true
+	}
+	return marked, nil
+}
+
+func (r *MongoBookmarkRepository) CreateList(list *domain.ReadingList) (*domain.ReadingList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if list.ID.IsZero() {
+		list.ID = primitive.NewObjectID()
+	}
+	if list.BlogIDs == nil {
+		list.BlogIDs = []primitive.ObjectID{}
+	}
+	if _, err := r.listsColl.InsertOne(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *MongoBookmarkRepository) GetList(id primitive.ObjectID) (*domain.ReadingList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var list domain.ReadingList
+	if err := r.listsColl.FindOne(ctx, bson.M{"_id": id}).Decode(&list); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrReadingListNotFound
+		}
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetLists returns a user's reading lists, oldest first
+func (r *MongoBookmarkRepository) GetLists(userID primitive.ObjectID, publicOnly bool) ([]*domain.ReadingList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	if publicOnly {
+		filter["public"] = true
+	}
+	cursor, err := r.listsColl.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	lists := []*domain.ReadingList{}
+	for cursor.Next(ctx) {
+		var l domain.ReadingList
+		if err := cursor.Decode(&l); err != nil {
+			return nil, err
+		}
+		lists = append(lists, &l)
+	}
+	return lists, nil
+}
+
+func (r *MongoBookmarkRepository) CountLists(userID primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	n, err := r.listsColl.CountDocuments(ctx, bson.M{"user_id": userID})
+	return int(n), err
+}
+
+// UpdateList saves the name, description and visibility of a list; blog_ids only change
+// through the atomic list operations so concurrent adds and removals are never overwritten
+func (r *MongoBookmarkRepository) UpdateList(list *domain.ReadingList) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.listsColl.UpdateByID(ctx, list.ID, bson.M{"$set": bson.M{
+		"name":        list.Name,
+		"description": list.Description,
+		"public":      list.Public,
+		"updated_at":  time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrReadingListNotFound
+	}
+	return nil
+}
+
+// ReorderList only matches while the list holds exactly the given blogs; blogIDs has no duplicates
+func (r *MongoBookmarkRepository) ReorderList(listID primitive.ObjectID, blogIDs []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	members := bson.M{"$size": len(blogIDs)}
+	if len(blogIDs) > 0 {
+		// $all with an empty array matches nothing
+		members["$all"] = blogIDs
+	}
+	res, err := r.listsColl.UpdateOne(ctx,
+		bson.M{"_id": listID, "blog_ids": members},
+		bson.M{"$set": bson.M{"blog_ids": blogIDs, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrInvalidListOrder
+	}
+	return nil
+}
+
+func (r *MongoBookmarkRepository) DeleteList(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.listsColl.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrReadingListNotFound
+	}
+	return nil
+}
+
+func (r *MongoBookmarkRepository) AddToList(listID, blogID primitive.ObjectID, position int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	push := bson.M{"$each": bson.A{blogID}}
+	if position >= 0 {
+		push["$position"] = position
+	}
+	// the filters make the push a no-op when the blog is already listed or the list is full
+	_, err := r.listsColl.UpdateOne(ctx,
+		bson.M{
+			"_id":      listID,
+			"blog_ids": bson.M{"$ne": blogID},
+			"blog_ids." + strconv.Itoa(domain.MaxReadingListItems-1): bson.M{"$exists": false},
+		},
+		bson.M{"$push": bson.M{"blog_ids": push}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	return err
+}
+
+func (r *MongoBookmarkRepository) RemoveFromList(listID, blogID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.listsColl.UpdateByID(ctx, listID, bson.M{
+		"$pull": bson.M{"blog_ids": blogID},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	return err
+}
+
+func (r *MongoBookmarkRepository) RemoveBlog(blogID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := r.col.DeleteMany(ctx, bson.M{"blog_id": blogID}); err != nil {
+		return err
+	}
+	_, err := r.listsColl.UpdateMany(ctx, bson.M{"blog_ids": blogID}, bson.M{"$pull": bson.M{"blog_ids": blogID}})
+	return err
+}
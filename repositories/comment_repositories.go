@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoCommentRepository struct {
+	col          *mongo.Collection
+	settingsColl *mongo.Collection
+}
+
+func NewMongoCommentRepository(col *mongo.Collection) *MongoCommentRepository {
+	return &MongoCommentRepository{col: col, settingsColl: col.Database().Collection("comment_settings")}
+}
+
+// blocked words are kept in a single settings document
+const blockedWordsDocID = "blocked_words"
+
+// create a comment
+func (r *MongoCommentRepository) CreateComment(comment *domain.Comment) (*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.col.InsertOne(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// retrieve a comment using its id
+func (r *MongoCommentRepository) GetCommentByID(id primitive.ObjectID) (*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var comment domain.Comment
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&comment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// GetCommentsByBlog lists the comments of a blog, oldest first; an empty status returns every state
+func (r *MongoCommentRepository) GetCommentsByBlog(blogID primitive.ObjectID, status string) ([]*domain.Comment, error) {
+	filter := bson.M{"blog_id": blogID}
+	if status != "" {
+		filter["status"] = status
+	}
+	return r.find(filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+// CountByBlogs counts comments in the given state for each of the blogs
+func (r *MongoCommentRepository) CountByBlogs(blogIDs []primitive.ObjectID, status string) (map[primitive.ObjectID]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	counts := map[primitive.ObjectID]int{}
+	if len(blogIDs) == 0 {
+		return counts, nil
+	}
+
+	cursor, err := r.col.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"blog_id": bson.M{"$in": blogIDs}, "status": status}},
+		bson.M{"$group": bson.M{"_id": "$blog_id", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var row struct {
+			BlogID primitive.ObjectID `bson:"_id"`
+			Count  int                `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		counts[row.BlogID] = row.Count
+	}
+	return counts, nil
+}
+
+// DeleteByBlog removes every comment on a blog, including replies and comments awaiting moderation
+func (r *MongoCommentRepository) DeleteByBlog(blogID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.DeleteMany(ctx, bson.M{"blog_id": blogID})
+	return err
+}
+
+// GetModerationQueue lists pending comments, restricted to one author's blogs when blogAuthorID is set
+func (r *MongoCommentRepository) GetModerationQueue(blogAuthorID *primitive.ObjectID) ([]*domain.Comment, error) {
+	filter := bson.M{"status": domain.CommentStatusPending}
+	if blogAuthorID != nil {
+		filter["blog_author_id"] = *blogAuthorID
+	}
+	return r.find(filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+// UpdateCommentStatus records a moderation decision
+func (r *MongoCommentRepository) UpdateCommentStatus(id primitive.ObjectID, status string, moderatorID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"status":       status,
+		"moderated_by": moderatorID,
+		"moderated_at": time.Now(),
+	}}
+	res, err := r.col.UpdateByID(ctx, id, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *MongoCommentRepository) GetBlockedWords() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var doc struct {
+		Words []string `bson:"words"`
+	}
+	err := r.settingsColl.FindOne(ctx, bson.M{"_id": blockedWordsDocID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return doc.Words, nil
+}
+
+func (r *MongoCommentRepository) SetBlockedWords(words []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.settingsColl.UpdateOne(ctx,
+		bson.M{"_id": blockedWordsDocID},
+		bson.M{"$set": bson.M{"words": words}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// helper to run a find and decode every comment
+func (r *MongoCommentRepository) find(filter bson.M, opts *options.FindOptions) ([]*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []*domain.Comment{}
+	for cursor.Next(ctx) {
+		var c domain.Comment
+		if err := cursor.Decode(&c); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &c)
+	}
+	return comments, nil
+}
@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDashboardRepository runs the author dashboard aggregations over the blog database
+type MongoDashboardRepository struct {
+	blogs        *mongo.Collection
+	interactions *mongo.Collection
+	comments     *mongo.Collection
+	stats        *mongo.Collection
+	follows      *mongo.Collection
+}
+
+func NewMongoDashboardRepository(db *mongo.Database) *MongoDashboardRepository {
+	return &MongoDashboardRepository{
+		blogs:        db.Collection("blogs"),
+		interactions: db.Collection("blog_interactions"),
+		comments:     db.Collection("comments"),
+		stats:        db.Collection("blog_stats"),
+		follows:      db.Collection("follows"),
+	}
+}
+
+func (r *MongoDashboardRepository) CountPostsByStatus(authorID primitive.ObjectID) (domain.PostStatusCounts, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	counts := domain.PostStatusCounts{}
+	cursor, err := r.blogs.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"user_id": authorID}},
+		// posts written before moderation existed have no status and count as published
+		bson.M{"$group": bson.M{"_id": bson.M{"$ifNull": bson.A{"$status", domain.BlogStatusPublished}}, "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return counts, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var row struct {
+			Status string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return counts, err
+		}
+		counts.Total += row.Count
+		switch row.Status {
+		case domain.BlogStatusHidden:
+			counts.Hidden += row.Count
+		default:
+			counts.Published += row.Count
+		}
+	}
+	return counts, nil
+}
+
+func (r *MongoDashboardRepository) GetTotals(authorID primitive.ObjectID) (domain.StatCounts, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	totals := domain.StatCounts{}
+	cursor, err := r.blogs.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"user_id": authorID}},
+		bson.M{"$group": bson.M{
+			"_id":      nil,
+			"views":    bson.M{"$sum": "$view_count"},
+			"likes":    bson.M{"$sum": "$likes"},
+			"dislikes": bson.M{"$sum": "$dislikes"},
+		}},
+	})
+	if err != nil {
+		return totals, err
+	}
+	defer cursor.Close(ctx)
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&totals); err != nil {
+			return totals, err
+		}
+	}
+
+	comments, err := r.comments.CountDocuments(ctx, bson.M{"blog_author_id": authorID, "status": domain.CommentStatusApproved})
+	if err != nil {
+		return totals, err
+	}
+	totals.Comments = int(comments)
+	return totals, nil
+}
+
+// GetActivity takes views from the daily analytics buckets, likes and dislikes from the
+// interaction log and comments from the comment collection
+func (r *MongoDashboardRepository) GetActivity(authorID primitive.ObjectID, since time.Time) (domain.StatCounts, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	activity := domain.StatCounts{}
+	blogIDs, err := r.authorBlogIDs(ctx, authorID)
+	if err != nil || len(blogIDs) == 0 {
+		return activity, err
+	}
+
+	cursor, err := r.stats.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{
+			"blog_id":     bson.M{"$in": blogIDs},
+			"granularity": domain.GranularityDay,
+			"start":       bson.M{"$gte": since},
+		}},
+		bson.M{"$group": bson.M{"_id": nil, "views": bson.M{"$sum": "$views"}}},
+	})
+	if err != nil {
+		return activity, err
+	}
+	if cursor.Next(ctx) {
+		var row struct {
+			Views int `bson:"views"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			cursor.Close(ctx)
+			return activity, err
+		}
+		activity.Views = row.Views
+	}
+	cursor.Close(ctx)
+
+	cursor, err = r.interactions.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"blog_id": bson.M{"$in": blogIDs}, "created_at": bson.M{"$gte": since}}},
+		bson.M{"$group": bson.M{"_id": "$action", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return activity, err
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			Action string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			cursor.Close(ctx)
+			return activity, err
+		}
+		switch row.Action {
+		case domain.ReactionLike:
+			activity.Likes = row.Count
+		case domain.ReactionDislike:
+			activity.Dislikes = row.Count
+		}
+	}
+	cursor.Close(ctx)
+
+	comments, err := r.comments.CountDocuments(ctx, bson.M{
+		"blog_author_id": authorID,
+		"status":         domain.CommentStatusApproved,
+		"created_at":     bson.M{"$gte": since},
+	})
+	if err != nil {
+		return activity, err
+	}
+	activity.Comments = int(comments)
+	return activity, nil
+}
+
+// GetTopPosts returns the author's most liked posts, most viewed first among equals
+func (r *MongoDashboardRepository) GetTopPosts(authorID primitive.ObjectID, limit int) ([]*domain.Blog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "likes", Value: -1}, {Key: "view_count", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.blogs.Find(ctx, bson.M{"user_id": authorID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blogs := []*domain.Blog{}
+	for cursor.Next(ctx) {
+		var b domain.Blog
+		if err := cursor.Decode(&b); err != nil {
+			return nil, err
+		}
+		blogs = append(blogs, &b)
+	}
+	return blogs, nil
+}
+
+// GetRecentComments lists the newest comments on the author's posts in any moderation state
+func (r *MongoDashboardRepository) GetRecentComments(authorID primitive.ObjectID, limit int) ([]*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.comments.Find(ctx, bson.M{"blog_author_id": authorID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []*domain.Comment{}
+	for cursor.Next(ctx) {
+		var c domain.Comment
+		if err := cursor.Decode(&c); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &c)
+	}
+	return comments, nil
+}
+
+// GetFollowerStats counts the author's followers and how many joined on each day since the given time
+func (r *MongoDashboardRepository) GetFollowerStats(authorID primitive.ObjectID, since time.Time) (domain.FollowerStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stats := domain.FollowerStats{}
+	filter := bson.M{"type": domain.FollowAuthor, "author_id": authorID}
+	total, err := r.follows.CountDocuments(ctx, filter)
+	if err != nil {
+		return stats, err
+	}
+	stats.Total = int(total)
+
+	growth, err := dailyCounts(ctx, r.follows, bson.M{"type": domain.FollowAuthor, "author_id": authorID, "created_at": bson.M{"$gte": since}}, "$created_at")
+	if err != nil {
+		return stats, err
+	}
+	stats.Growth = growth
+	return stats, nil
+}
+
+func (r *MongoDashboardRepository) authorBlogIDs(ctx context.Context, authorID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	cursor, err := r.blogs.Find(ctx, bson.M{"user_id": authorID}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var row struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		ids = append(ids, row.ID)
+	}
+	return ids, nil
+}
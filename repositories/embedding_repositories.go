@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoEmbeddingRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoEmbeddingRepository(col *mongo.Collection) *MongoEmbeddingRepository {
+	return &MongoEmbeddingRepository{col: col}
+}
+
+// SaveEmbedding stores or replaces the vector of a blog
+func (r *MongoEmbeddingRepository) SaveEmbedding(e *domain.BlogEmbedding) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.col.ReplaceOne(ctx, bson.M{"_id": e.BlogID}, e, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoEmbeddingRepository) DeleteEmbedding(blogID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.col.DeleteOne(ctx, bson.M{"_id": blogID})
+	return err
+}
+
+// GetEmbeddings loads every vector produced by the given model
+func (r *MongoEmbeddingRepository) GetEmbeddings(model string) ([]*domain.BlogEmbedding, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Find(ctx, bson.M{"model": model})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	embeddings := []*domain.BlogEmbedding{}
+	for cursor.Next(ctx) {
+		var e domain.BlogEmbedding
+		if err := cursor.Decode(&e); err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, &e)
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoFollowRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoFollowRepository(col *mongo.Collection) *MongoFollowRepository {
+	return &MongoFollowRepository{col: col}
+}
+
+func (r *MongoFollowRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "type", Value: 1}, {Key: "author_id", Value: 1}, {Key: "tag", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// follower counts and growth on the author dashboard
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "author_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	return err
+}
+
+// followKey identifies a follow regardless of when it was made
+func followKey(f *domain.Follow) bson.M {
+	key := bson.M{"follower_id": f.FollowerID, "type": f.Type}
+	if f.Type == domain.FollowAuthor {
+		key["author_id"] = f.AuthorID
+	} else {
+		key["tag"] = f.Tag
+	}
+	return key
+}
+
+func (r *MongoFollowRepository) Follow(f *domain.Follow) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.col.UpdateOne(ctx, followKey(f),
+		bson.M{"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "created_at": f.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *MongoFollowRepository) Unfollow(f *domain.Follow) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.col.DeleteOne(ctx, followKey(f))
+	return err
+}
+
+// GetFollowing lists what a user follows, most recent first
+func (r *MongoFollowRepository) GetFollowing(followerID primitive.ObjectID) ([]*domain.Follow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Find(ctx, bson.M{"follower_id": followerID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(domain.MaxFollows))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	follows := []*domain.Follow{}
+	for cursor.Next(ctx) {
+		var f domain.Follow
+		if err := cursor.Decode(&f); err != nil {
+			return nil, err
+		}
+		follows = append(follows, &f)
+	}
+	return follows, nil
+}
+
+func (r *MongoFollowRepository) CountFollowing(followerID primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	n, err := r.col.CountDocuments(ctx, bson.M{"follower_id": followerID})
+	return int(n), err
+}
+
+func (r *MongoFollowRepository) CountFollowers(authorID primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	n, err := r.col.CountDocuments(ctx, bson.M{"type": domain.FollowAuthor, "author_id": authorID})
+	return int(n), err
+}
+
+func (r *MongoFollowRepository) GetPopularAuthors(minFollowers int) ([]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"type": domain.FollowAuthor}},
+		bson.M{"$group": bson.M{"_id": "$author_id", "followers": bson.M{"$sum": 1}}},
+		bson.M{"$match": bson.M{"followers": bson.M{"$gte": minFollowers}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	authors := []primitive.ObjectID{}
+	for cursor.Next(ctx) {
+		var row struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		authors = append(authors, row.ID)
+	}
+	return authors, nil
+}
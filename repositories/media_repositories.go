@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// media libraries are listed newest first
+var mediaSortKeys = []sortKey{{Field: "created_at", Desc: true}, {Field: "_id", Desc: true}}
+
+type MongoMediaRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoMediaRepository(col *mongo.Collection) *MongoMediaRepository {
+	return &MongoMediaRepository{col: col}
+}
+
+func (r *MongoMediaRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoMediaRepository) CreateMedia(media *domain.Media) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if media.ID.IsZero() {
+		media.ID = primitive.NewObjectID()
+	}
+	_, err := r.col.InsertOne(ctx, media)
+	return err
+}
+
+func (r *MongoMediaRepository) GetMedia(id primitive.ObjectID) (*domain.Media, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var media domain.Media
+	if err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&media); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrMediaNotFound
+		}
+		return nil, err
+	}
+	return &media, nil
+}
+
+// GetMediaByIDs loads the existing media among ids, in no particular order
+func (r *MongoMediaRepository) GetMediaByIDs(ids []primitive.ObjectID) ([]*domain.Media, error) {
+	if len(ids) == 0 {
+		return []*domain.Media{}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	media := []*domain.Media{}
+	for cursor.Next(ctx) {
+		var m domain.Media
+		if err := cursor.Decode(&m); err != nil {
+			return nil, err
+		}
+		media = append(media, &m)
+	}
+	return media, nil
+}
+
+func (r *MongoMediaRepository) GetUserMedia(userID primitive.ObjectID, page domain.PageRequest) (*domain.Page[*domain.Media], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query, findOptions, err := pageQuery(bson.M{"user_id": userID}, "media", mediaSortKeys, page)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := r.col.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	media := []*domain.Media{}
+	for cursor.Next(ctx) {
+		var m domain.Media
+		if err := cursor.Decode(&m); err != nil {
+			return nil, err
+		}
+		media = append(media, &m)
+	}
+	return buildPage(media, page, "media", func(m *domain.Media) bson.A { return bson.A{m.CreatedAt, m.ID} }), nil
+}
+
+func (r *MongoMediaRepository) UpdateMedia(media *domain.Media) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.col.ReplaceOne(ctx, bson.M{"_id": media.ID}, media)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrMediaNotFound
+	}
+	return nil
+}
+
+func (r *MongoMediaRepository) GetPendingMedia(limit int) ([]*domain.Media, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.col.Find(ctx, bson.M{"status": domain.MediaProcessing}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	media := []*domain.Media{}
+	for cursor.Next(ctx) {
+		var m domain.Media
+		if err := cursor.Decode(&m); err != nil {
+			return nil, err
+		}
+		media = append(media, &m)
+	}
+	return media, nil
+}
+
+func (r *MongoMediaRepository) DeleteMedia(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.col.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrMediaNotFound
+	}
+	return nil
+}
+
+func (r *MongoMediaRepository) TotalSize(userID primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"user_id": userID}},
+		bson.M{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$size"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var row struct {
+		Total int64 `bson:"total"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&row); err != nil {
+			return 0, err
+		}
+	}
+	return row.Total, nil
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoNewsletterRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoNewsletterRepository(col *mongo.Collection) *MongoNewsletterRepository {
+	return &MongoNewsletterRepository{col: col}
+}
+
+func (r *MongoNewsletterRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}, {Key: "scope", Value: 1}, {Key: "author_id", Value: 1}, {Key: "tag", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "confirm_token", Value: 1}}},
+		{Keys: bson.D{{Key: "unsubscribe_token", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "last_sent_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoNewsletterRepository) FindSubscription(email, scope string, authorID primitive.ObjectID, tag string) (*domain.NewsletterSubscription, error) {
+	filter := bson.M{"email": email, "scope": scope, "author_id": bson.M{"$exists": false}, "tag": bson.M{"$exists": false}}
+	switch scope {
+	case domain.NewsletterScopeAuthor:
+		filter["author_id"] = authorID
+	case domain.NewsletterScopeTag:
+		filter["tag"] = tag
+	}
+	return r.findOne(filter)
+}
+
+// SaveSubscription inserts a new subscription or replaces an existing one
+func (r *MongoNewsletterRepository) SaveSubscription(s *domain.NewsletterSubscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if s.ID.IsZero() {
+		s.ID = primitive.NewObjectID()
+	}
+	_, err := r.col.ReplaceOne(ctx, bson.M{"_id": s.ID}, s, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoNewsletterRepository) GetByConfirmToken(token string) (*domain.NewsletterSubscription, error) {
+	return r.findOne(bson.M{"confirm_token": token})
+}
+
+func (r *MongoNewsletterRepository) GetByUnsubscribeToken(token string) (*domain.NewsletterSubscription, error) {
+	return r.findOne(bson.M{"unsubscribe_token": token})
+}
+
+func (r *MongoNewsletterRepository) GetDueSubscriptions(sentBefore time.Time, limit int) ([]*domain.NewsletterSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.col.Find(ctx,
+		bson.M{"status": domain.NewsletterConfirmed, "last_sent_at": bson.M{"$lte": sentBefore}},
+		options.Find().SetSort(bson.D{{Key: "last_sent_at", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subs := []*domain.NewsletterSubscription{}
+	for cursor.Next(ctx) {
+		var s domain.NewsletterSubscription
+		if err := cursor.Decode(&s); err != nil {
+			return nil, err
+		}
+		subs = append(subs, &s)
+	}
+	return subs, nil
+}
+
+func (r *MongoNewsletterRepository) MarkSent(id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.col.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_sent_at": at}})
+	return err
+}
+
+// findOne returns nil without an error when nothing matches
+func (r *MongoNewsletterRepository) findOne(filter bson.M) (*domain.NewsletterSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var s domain.NewsletterSubscription
+	if err := r.col.FindOne(ctx, filter).Decode(&s); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// notifications are removed by a TTL index once they are this old
+const notificationRetention = 180 * 24 * time.Hour
+
+// newest first
+var notificationSortKeys = []sortKey{{Field: "created_at", Desc: true}, {Field: "_id", Desc: true}}
+
+type MongoNotificationRepository struct {
+	col      *mongo.Collection
+	prefColl *mongo.Collection
+}
+
+func NewMongoNotificationRepository(col *mongo.Collection) *MongoNotificationRepository {
+	return &MongoNotificationRepository{col: col, prefColl: col.Database().Collection("notification_preferences")}
+}
+
+func (r *MongoNotificationRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(notificationRetention.Seconds()))},
+	})
+	return err
+}
+
+func (r *MongoNotificationRepository) CreateNotification(n *domain.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if n.ID.IsZero() {
+		n.ID = primitive.NewObjectID()
+	}
+	_, err := r.col.InsertOne(ctx, n)
+	return err
+}
+
+func (r *MongoNotificationRepository) GetNotifications(userID primitive.ObjectID, unreadOnly bool, page domain.PageRequest) (*domain.Page[*domain.Notification], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	query, findOptions, err := pageQuery(filter, "notifications", notificationSortKeys, page)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := r.col.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []*domain.Notification{}
+	for cursor.Next(ctx) {
+		var n domain.Notification
+		if err := cursor.Decode(&n); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &n)
+	}
+	return buildPage(notifications, page, "notifications", func(n *domain.Notification) bson.A { return bson.A{n.CreatedAt, n.ID} }), nil
+}
+
+func (r *MongoNotificationRepository) CountUnread(userID primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	n, err := r.col.CountDocuments(ctx, bson.M{"user_id": userID, "read": false})
+	return int(n), err
+}
+
+// MarkRead marks one of the user's notifications as read; marking it again is a no-op
+func (r *MongoNotificationRepository) MarkRead(userID, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID},
+		bson.A{bson.M{"$set": bson.M{
+			"read":    true,
+			"read_at": bson.M{"$ifNull": bson.A{"$read_at", time.Now()}},
+		}}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *MongoNotificationRepository) MarkAllRead(userID primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := r.col.UpdateMany(ctx,
+		bson.M{"user_id": userID, "read": false},
+		bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(res.ModifiedCount), nil
+}
+
+func (r *MongoNotificationRepository) GetPreferences(userID primitive.ObjectID) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var doc struct {
+		Types map[string]bool `bson:"types"`
+	}
+	err := r.prefColl.FindOne(ctx, bson.M{"_id": userID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if doc.Types == nil {
+		doc.Types = map[string]bool{}
+	}
+	return doc.Types, nil
+}
+
+// SetPreferences merges the given switches into the stored ones
+func (r *MongoNotificationRepository) SetPreferences(userID primitive.ObjectID, prefs map[string]bool) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	set := bson.M{"updated_at": time.Now()}
+	for kind, enabled := range prefs {
+		set["types."+kind] = enabled
+	}
+	_, err := r.prefColl.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$set": set}, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"encoding/base64"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sortKey is one field of a keyset sort; the last key must be unique (usually _id)
+type sortKey struct {
+	Field string
+	Desc  bool
+}
+
+// cursorPayload is what an opaque cursor decodes to: the sort it belongs to and the
+// sort-key values of the last item on the previous page
+type cursorPayload struct {
+	Sort   string `bson:"s"`
+	Values bson.A `bson:"v"`
+}
+
+func encodeCursor(sort string, values bson.A) string {
+	raw, err := bson.Marshal(cursorPayload{Sort: sort, Values: values})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor rejects cursors that are malformed or were issued for a different sort
+func decodeCursor(cursor, sort string, keys []sortKey) (bson.A, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := bson.Unmarshal(raw, &payload); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	if payload.Sort != sort || len(payload.Values) != len(keys) {
+		return nil, domain.ErrInvalidCursor
+	}
+	return payload.Values, nil
+}
+
+// keysetFilter matches documents strictly after the cursor position:
+// (k1 after v1) OR (k1 = v1 AND k2 after v2) OR ...
+func keysetFilter(keys []sortKey, values bson.A) bson.M {
+	or := make([]bson.M, 0, len(keys))
+	for i, key := range keys {
+		clause := bson.M{}
+		for j := 0; j < i; j++ {
+			clause[keys[j].Field] = values[j]
+		}
+		op := "$gt"
+		if key.Desc {
+			op = "$lt"
+		}
+		clause[key.Field] = bson.M{op: values[i]}
+		or = append(or, clause)
+	}
+	return bson.M{"$or": or}
+}
+
+// sortDoc turns sort keys into a Mongo sort document
+func sortDoc(keys []sortKey) bson.D {
+	d := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		dir := 1
+		if key.Desc {
+			dir = -1
+		}
+		d = append(d, bson.E{Key: key.Field, Value: dir})
+	}
+	return d
+}
+
+// pageQuery combines the base filter with the cursor position and returns the find
+// options for one page; it fetches one extra document to learn whether more pages exist
+func pageQuery(filter bson.M, sort string, keys []sortKey, page domain.PageRequest) (bson.M, *options.FindOptions, error) {
+	page = page.Normalize()
+	if page.Cursor != "" {
+		values, err := decodeCursor(page.Cursor, sort, keys)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter = bson.M{"$and": []bson.M{filter, keysetFilter(keys, values)}}
+	}
+	opts := options.Find().SetSort(sortDoc(keys)).SetLimit(int64(page.Limit + 1))
+	return filter, opts, nil
+}
+
+// buildPage trims the extra document fetched by pageQuery and sets the next cursor
+func buildPage[T any](items []T, page domain.PageRequest, sort string, values func(T) bson.A) *domain.Page[T] {
+	page = page.Normalize()
+	result := &domain.Page[T]{Items: items, Limit: page.Limit}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	if len(items) > page.Limit {
+		result.Items = items[:page.Limit]
+		result.HasMore = true
+		result.NextCursor = encodeCursor(sort, values(result.Items[page.Limit-1]))
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoReportRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoReportRepository(col *mongo.Collection) *MongoReportRepository {
+	return &MongoReportRepository{col: col}
+}
+
+// create a report
+func (r *MongoReportRepository) CreateReport(report *domain.Report) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if report.ID.IsZero() {
+		report.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.col.InsertOne(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// retrieve a report using its id
+func (r *MongoReportRepository) GetReportByID(id primitive.ObjectID) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var report domain.Report
+	err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&report)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrReportNotFound
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetReports lists reports oldest first; an empty status returns every state
+func (r *MongoReportRepository) GetReports(status string) ([]*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reports := []*domain.Report{}
+	for cursor.Next(ctx) {
+		var report domain.Report
+		if err := cursor.Decode(&report); err != nil {
+			return nil, err
+		}
+		reports = append(reports, &report)
+	}
+	return reports, nil
+}
+
+// UpdateReportStatus records the admin's triage decision
+func (r *MongoReportRepository) UpdateReportStatus(id primitive.ObjectID, status string, adminID primitive.ObjectID, note string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"resolved_by": adminID,
+		"resolved_at": time.Now(),
+		"note":        note,
+	}}
+	res, err := r.col.UpdateByID(ctx, id, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrReportNotFound
+	}
+	return nil
+}
+
+// CountReports counts the reports on a blog in the given state
+func (r *MongoReportRepository) CountReports(blogID primitive.ObjectID, status string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.col.CountDocuments(ctx, bson.M{"blog_id": blogID, "status": status})
+}
+
+// HasOpenReport checks whether a reader already has a pending report on a blog
+func (r *MongoReportRepository) HasOpenReport(reporterID, blogID primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := r.col.CountDocuments(ctx, bson.M{
+		"reporter_id": reporterID,
+		"blog_id":     blogID,
+		"status":      domain.ReportStatusOpen,
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"Blog/domain"
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MongoSearchEngine adapts the blog collection's text index to domain.SearchEngine.
+// Mongo keeps the index up to date itself, so Index and Remove do nothing.
+type MongoSearchEngine struct {
+	repo *MongoBlogRepository
+}
+
+func NewMongoSearchEngine(repo *MongoBlogRepository) *MongoSearchEngine {
+	return &MongoSearchEngine{repo: repo}
+}
+
+func (e *MongoSearchEngine) Index(blog *domain.Blog) error {
+	return nil
+}
+
+func (e *MongoSearchEngine) Remove(blogID primitive.ObjectID) error {
+	return nil
+}
+
+func (e *MongoSearchEngine) Search(terms []string, page domain.PageRequest) (*domain.Page[*domain.SearchHit], error) {
+	return e.repo.SearchBlogs(terms, page)
+}
+
+// Suggest completes a prefix to existing tags, most used first
+func (e *MongoSearchEngine) Suggest(prefix string, limit int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return []string{}, nil
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	pattern := "^" + regexp.QuoteMeta(prefix)
+	match := visibleFilter()
+	match["tags"] = bson.M{"$regex": pattern, "$options": "i"}
+
+	cursor, err := e.repo.col.Aggregate(ctx, bson.A{
+		bson.M{"$match": match},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$match": bson.M{"tags": bson.M{"$regex": pattern, "$options": "i"}}},
+		bson.M{"$group": bson.M{"_id": bson.M{"$toLower": "$tags"}, "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		Tag   string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	words := make([]string, 0, len(rows))
+	for _, row := range rows {
+		words = append(words, row.Tag)
+	}
+	return words, nil
+}
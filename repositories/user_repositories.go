@@ -102,11 +102,19 @@ func (r *MongoUserRepository) GetByID(id primitive.ObjectID) (*domain.User, erro
 	return &user, nil
 }
 
-func (r *MongoUserRepository) GetAll() ([]*domain.User, error) {
+// users are listed in creation order
+var userSortKeys = []sortKey{{Field: "_id"}}
+
+func (r *MongoUserRepository) GetAll(page domain.PageRequest) (*domain.Page[*domain.User], error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cursor, err := r.Collection.Find(ctx, bson.M{})
+	query, findOptions, err := pageQuery(bson.M{}, "id", userSortKeys, page)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.Collection.Find(ctx, query, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +128,7 @@ func (r *MongoUserRepository) GetAll() ([]*domain.User, error) {
 		}
 		users = append(users, &user)
 	}
-	return users, nil
+	return buildPage(users, page, "id", func(u *domain.User) bson.A { return bson.A{u.ID} }), nil
 }
 
 func (r *MongoUserRepository) PromoteUser(userID primitive.ObjectID, newRole string) error {
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"log"
+	"time"
+)
+
+// admin statistics are expensive to aggregate and only need to be roughly current
+const (
+	adminStatsCacheTTL    = time.Minute
+	adminStatsDefaultDays = 30
+	adminStatsMaxDays     = 365
+	adminStatsTopTags     = 10
+)
+
+type AdminStatsUsecase struct {
+	repo  domain.AdminStatsRepository
+	cache *ttlCache[int, *domain.AdminStats]
+}
+
+// constructor for AdminStatsUsecase; AI suggestion requests are recorded as they happen
+func NewAdminStatsUsecase(repo domain.AdminStatsRepository, events domain.EventBus) *AdminStatsUsecase {
+	u := &AdminStatsUsecase{repo: repo, cache: newTTLCache[int, *domain.AdminStats](adminStatsCacheTTL)}
+	if events != nil {
+		events.Subscribe(domain.EventAISuggestion, func(e domain.Event) {
+			failed, _ := e.Payload.(bool)
+			if err := repo.RecordAISuggestion(e.ActorID, failed, e.OccurredAt); err != nil {
+				log.Println("recording AI suggestion usage failed:", err)
+			}
+		})
+	}
+	return u
+}
+
+// GetStats reports site activity over the last days days (0 for the default), served
+// from cache when fresh
+func (u *AdminStatsUsecase) GetStats(days int) (*domain.AdminStats, error) {
+	if days == 0 {
+		days = adminStatsDefaultDays
+	}
+	if days < 0 || days > adminStatsMaxDays {
+		return nil, statsRangeError("days must be between 1 and %d", adminStatsMaxDays)
+	}
+	if stats, ok := u.cache.Get(days); ok {
+		return stats, nil
+	}
+
+	now := time.Now().UTC()
+	today := truncateBucket(now, domain.GranularityDay)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	stats := &domain.AdminStats{Since: since, Days: days, GeneratedAt: now}
+	var err error
+	var signups, posts, suggestions []domain.DailyCount
+
+	if stats.Users.Total, signups, err = u.repo.CountSignups(since); err != nil {
+		return nil, err
+	}
+	if stats.Authors.Total, stats.Authors.Active, err = u.repo.CountAuthors(since); err != nil {
+		return nil, err
+	}
+	if stats.Posts.Total, posts, err = u.repo.CountPosts(since); err != nil {
+		return nil, err
+	}
+	if stats.TopTags, err = u.repo.TopTags(since, adminStatsTopTags); err != nil {
+		return nil, err
+	}
+	interactions, err := u.repo.InteractionVolume(since)
+	if err != nil {
+		return nil, err
+	}
+	if stats.AISuggestions.Total, stats.AISuggestions.Failed, suggestions, err = u.repo.AISuggestionUsage(since); err != nil {
+		return nil, err
+	}
+
+	stats.Users.Signups = fillDays(signups, since, today)
+	stats.Posts.PerDay = fillDays(posts, since, today)
+	stats.AISuggestions.PerDay = fillDays(suggestions, since, today)
+
+	byDay := map[time.Time]*domain.StatsBucket{}
+	for _, b := range interactions {
+		byDay[b.Start.UTC()] = b
+	}
+	for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+		b, ok := byDay[day]
+		if !ok {
+			b = &domain.StatsBucket{Granularity: domain.GranularityDay}
+		}
+		b.Start = day
+		stats.Interactions.PerDay = append(stats.Interactions.PerDay, b)
+		stats.Interactions.Totals.Add(b.StatCounts)
+	}
+
+	u.cache.Set(days, stats)
+	return stats, nil
+}
+
+// fillDays returns one entry per day from start to end inclusive, zero where nothing happened
+func fillDays(counts []domain.DailyCount, start, end time.Time) []domain.DailyCount {
+	byDay := map[time.Time]int{}
+	for _, c := range counts {
+		byDay[c.Day.UTC()] = c.Count
+	}
+	out := []domain.DailyCount{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		out = append(out, domain.DailyCount{Day: day, Count: byDay[day]})
+	}
+	return out
+}
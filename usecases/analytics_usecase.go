@@ -0,0 +1,310 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// default ranges and the most buckets a single stats request may span
+const (
+	defaultHourlyRange = 48 * time.Hour
+	defaultDailyRange  = 30 * 24 * time.Hour
+	maxStatsBuckets    = 750
+	breakdownLimit     = 10
+)
+
+type bucketKey struct {
+	blogID      primitive.ObjectID
+	granularity string
+	start       time.Time
+}
+
+type breakdownKey struct {
+	blogID    primitive.ObjectID
+	day       time.Time
+	dimension string
+	value     string
+}
+
+// AnalyticsUsecase rolls reader interactions up into hourly and daily counters per blog.
+// Increments are buffered in memory and written in batches.
+type AnalyticsUsecase struct {
+	repo     domain.AnalyticsRepository
+	blogRepo domain.BlogRepository
+	userRepo domain.UserRepository
+	geo      domain.GeoLocator
+
+	mu         sync.Mutex
+	buckets    map[bucketKey]*domain.StatsBucket
+	breakdowns map[breakdownKey]int
+}
+
+// constructor for AnalyticsUsecase; geo may be nil when no GeoIP database is configured
+func NewAnalyticsUsecase(repo domain.AnalyticsRepository, blogRepo domain.BlogRepository, userRepo domain.UserRepository, geo domain.GeoLocator, events domain.EventBus) *AnalyticsUsecase {
+	u := &AnalyticsUsecase{
+		repo:       repo,
+		blogRepo:   blogRepo,
+		userRepo:   userRepo,
+		geo:        geo,
+		buckets:    map[bucketKey]*domain.StatsBucket{},
+		breakdowns: map[breakdownKey]int{},
+	}
+	if events != nil {
+		events.Subscribe(domain.EventBlogViewed, u.recordView)
+		events.Subscribe(domain.EventBlogLiked, func(e domain.Event) {
+			u.record(e.BlogID, e.OccurredAt, func(c *domain.StatCounts) { c.Likes++ })
+		})
+		events.Subscribe(domain.EventBlogDisliked, func(e domain.Event) {
+			u.record(e.BlogID, e.OccurredAt, func(c *domain.StatCounts) { c.Dislikes++ })
+		})
+		// only published comments count: held ones when they are approved, never if rejected
+		countComment := func(e domain.Event) {
+			u.record(e.BlogID, e.OccurredAt, func(c *domain.StatCounts) { c.Comments++ })
+		}
+		events.Subscribe(domain.EventCommentCreated, func(e domain.Event) {
+			if comment, ok := e.Payload.(*domain.Comment); ok && comment.Status == domain.CommentStatusApproved {
+				countComment(e)
+			}
+		})
+		events.Subscribe(domain.EventCommentApproved, countComment)
+	}
+	return u
+}
+
+func (u *AnalyticsUsecase) recordView(e domain.Event) {
+	u.record(e.BlogID, e.OccurredAt, func(c *domain.StatCounts) { c.Views++ })
+
+	viewer, _ := e.Payload.(domain.Viewer)
+	country := ""
+	if u.geo != nil {
+		country = u.geo.Country(viewer.IP)
+	}
+	if country == "" {
+		country = "unknown"
+	}
+
+	day := truncateBucket(e.OccurredAt, domain.GranularityDay)
+	u.mu.Lock()
+	u.breakdowns[breakdownKey{e.BlogID, day, domain.DimensionReferrer, referrerHost(viewer.Referrer)}]++
+	u.breakdowns[breakdownKey{e.BlogID, day, domain.DimensionCountry, country}]++
+	u.mu.Unlock()
+}
+
+// record applies an increment to both the hourly and the daily bucket of a moment
+func (u *AnalyticsUsecase) record(blogID primitive.ObjectID, at time.Time, inc func(*domain.StatCounts)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, granularity := range []string{domain.GranularityHour, domain.GranularityDay} {
+		key := bucketKey{blogID, granularity, truncateBucket(at, granularity)}
+		b, ok := u.buckets[key]
+		if !ok {
+			b = &domain.StatsBucket{BlogID: blogID, Granularity: granularity, Start: key.start}
+			u.buckets[key] = b
+		}
+		inc(&b.StatCounts)
+	}
+}
+
+// referrerHost reduces a referrer URL to its host; no referrer means direct traffic
+func referrerHost(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return "direct"
+	}
+	parsed, err := url.Parse(referrer)
+	if err != nil || parsed.Hostname() == "" {
+		return "other"
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+func truncateBucket(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == domain.GranularityHour {
+		return t.Truncate(time.Hour)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func bucketStep(granularity string) time.Duration {
+	if granularity == domain.GranularityHour {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Flush writes the buffered counters; on failure they are kept for the next flush
+func (u *AnalyticsUsecase) Flush() error {
+	u.mu.Lock()
+	pendingBuckets, pendingBreakdowns := u.buckets, u.breakdowns
+	u.buckets = map[bucketKey]*domain.StatsBucket{}
+	u.breakdowns = map[breakdownKey]int{}
+	u.mu.Unlock()
+
+	if len(pendingBuckets) == 0 && len(pendingBreakdowns) == 0 {
+		return nil
+	}
+
+	buckets := make([]*domain.StatsBucket, 0, len(pendingBuckets))
+	for _, b := range pendingBuckets {
+		buckets = append(buckets, b)
+	}
+	breakdowns := make([]*domain.BreakdownCount, 0, len(pendingBreakdowns))
+	for k, n := range pendingBreakdowns {
+		breakdowns = append(breakdowns, &domain.BreakdownCount{BlogID: k.blogID, Day: k.day, Dimension: k.dimension, Value: k.value, Count: n})
+	}
+
+	if err := u.repo.AddStats(buckets, breakdowns); err != nil {
+		u.mu.Lock()
+		for k, b := range pendingBuckets {
+			if cur, ok := u.buckets[k]; ok {
+				cur.Add(b.StatCounts)
+			} else {
+				u.buckets[k] = b
+			}
+		}
+		for k, n := range pendingBreakdowns {
+			u.breakdowns[k] += n
+		}
+		u.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until stop is closed, flushing once more on the way out
+func (u *AnalyticsUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := u.Flush(); err != nil {
+				log.Println("analytics flush failed:", err)
+			}
+		case <-stop:
+			if err := u.Flush(); err != nil {
+				log.Println("analytics flush failed:", err)
+			}
+			return
+		}
+	}
+}
+
+// GetBlogStats reports a blog's interactions over [from, to] in hourly or daily buckets,
+// with top referrers and countries. Only the author and admins may see it.
+func (u *AnalyticsUsecase) GetBlogStats(userID, blogID, from, to, granularity string) (*domain.BlogStats, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	blog, err := u.blogRepo.GetBlogByID(bid)
+	if err != nil {
+		return nil, errors.New("blog not found")
+	}
+	if blog.UserID != uid {
+		user, err := u.userRepo.GetByID(uid)
+		if err != nil || user.Role != "admin" {
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	if granularity == "" {
+		granularity = domain.GranularityDay
+	}
+	if granularity != domain.GranularityHour && granularity != domain.GranularityDay {
+		return nil, statsRangeError("granularity must be hour or day")
+	}
+
+	end := time.Now()
+	if to != "" {
+		if end, err = parseStatsTime(to, true); err != nil {
+			return nil, err
+		}
+	}
+	start := end.Add(-defaultDailyRange)
+	if granularity == domain.GranularityHour {
+		start = end.Add(-defaultHourlyRange)
+	}
+	if from != "" {
+		if start, err = parseStatsTime(from, false); err != nil {
+			return nil, err
+		}
+	}
+	if !start.Before(end) {
+		return nil, statsRangeError("from must be before to")
+	}
+
+	// widen to whole buckets
+	step := bucketStep(granularity)
+	start = truncateBucket(start, granularity)
+	end = truncateBucket(end, granularity).Add(step)
+	if int(end.Sub(start)/step) > maxStatsBuckets {
+		return nil, statsRangeError("range spans more than %d buckets, use a coarser granularity", maxStatsBuckets)
+	}
+
+	// counters still buffered in memory are not included until the next flush
+	stored, err := u.repo.GetBuckets(bid, granularity, start, end)
+	if err != nil {
+		return nil, err
+	}
+	byStart := make(map[time.Time]*domain.StatsBucket, len(stored))
+	for _, b := range stored {
+		byStart[b.Start.UTC()] = b
+	}
+
+	stats := &domain.BlogStats{BlogID: bid, Granularity: granularity, From: start, To: end}
+	for t := start; t.Before(end); t = t.Add(step) {
+		b, ok := byStart[t]
+		if !ok {
+			b = &domain.StatsBucket{Start: t}
+		}
+		b.Start = t
+		stats.Series = append(stats.Series, b)
+		stats.Totals.Add(b.StatCounts)
+	}
+
+	// breakdowns are kept per day
+	dayStart, dayEnd := truncateBucket(start, domain.GranularityDay), end
+	if stats.Referrers, err = u.repo.GetBreakdown(bid, domain.DimensionReferrer, dayStart, dayEnd, breakdownLimit); err != nil {
+		return nil, err
+	}
+	if stats.Countries, err = u.repo.GetBreakdown(bid, domain.DimensionCountry, dayStart, dayEnd, breakdownLimit); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func statsRangeError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", domain.ErrInvalidStatsRange, fmt.Sprintf(format, args...))
+}
+
+// parseStatsTime accepts RFC 3339 or a plain date; a plain "to" date includes the whole day
+func parseStatsTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, statsRangeError("invalid date %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,365 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// limits that keep a single filter from turning into an expensive query
+const (
+	maxFilterDepth      = 5
+	maxFilterConditions = 32
+	maxFilterNodes      = 64 // and/or/not nodes count too, even empty ones
+	maxFilterValues     = 20
+	maxFilterTextLength = 200
+)
+
+// ParseBlogFilter parses the query-string form of a blog filter, for example
+//
+//	tag:go AND (author:64eabc0c6c7f4a76b6c133aa OR likes>=10) AND NOT tag:java
+//
+// Conditions are field:value or field>=number; juxtaposed conditions are ANDed,
+// AND binds tighter than OR, and parentheses group. Supported fields:
+//
+//	tag, tags.any   comma-separated tags, any of which must be present
+//	tags.all        comma-separated tags, all of which must be present
+//	tags.none       comma-separated tags, none of which may be present
+//	author          comma-separated author ids
+//	likes, views    with >= or > and a number
+//	status          published or hidden
+//	text            free text, quote it to include spaces: text:"clean code"
+//	from, to        date_created bounds as YYYY-MM-DD or RFC 3339
+//
+// Bare words are treated as free text.
+func ParseBlogFilter(expr string) (*domain.BlogFilter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return &domain.BlogFilter{}, nil
+	}
+
+	p := &filterParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, filterError("unexpected %q", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+func filterError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", domain.ErrInvalidFilter, fmt.Sprintf(format, args...))
+}
+
+// tokenizeFilter splits on whitespace and parentheses, keeping quoted values together
+func tokenizeFilter(expr string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote := false
+
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case inQuote:
+			if r == '"' {
+				inQuote = false
+			}
+			cur.WriteRune(r)
+		case r == '"':
+			inQuote = true
+			cur.WriteRune(r)
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, filterError("unterminated quote")
+	}
+	flush()
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// or := and ("OR" and)*
+func (p *filterParser) parseOr() (*domain.BlogFilter, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*domain.BlogFilter{first}
+	for p.peek() == "OR" {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &domain.BlogFilter{Or: nodes}, nil
+}
+
+// and := unary (["AND"] unary)*
+func (p *filterParser) parseAnd() (*domain.BlogFilter, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*domain.BlogFilter{first}
+	for {
+		tok := p.peek()
+		if tok == "AND" {
+			p.pos++
+		} else if tok == "" || tok == "OR" || tok == ")" {
+			break
+		}
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &domain.BlogFilter{And: nodes}, nil
+}
+
+// unary := "NOT" unary | "(" or ")" | condition
+func (p *filterParser) parseUnary() (*domain.BlogFilter, error) {
+	tok := p.peek()
+	switch tok {
+	case "":
+		return nil, filterError("unexpected end of filter")
+	case "NOT":
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &domain.BlogFilter{Not: inner}, nil
+	case "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, filterError("missing closing parenthesis")
+		}
+		p.pos++
+		return inner, nil
+	case ")", "AND", "OR":
+		return nil, filterError("unexpected %q", tok)
+	}
+	p.pos++
+	return parseCondition(tok)
+}
+
+var conditionPattern = regexp.MustCompile(`^([a-z_.]+)(:|>=|>)(.+)$`)
+
+// parseCondition turns one field:value token into a leaf node
+func parseCondition(tok string) (*domain.BlogFilter, error) {
+	m := conditionPattern.FindStringSubmatch(tok)
+	if m == nil {
+		return &domain.BlogFilter{Text: strings.Trim(tok, `"`)}, nil
+	}
+	field, op, value := m[1], m[2], strings.Trim(m[3], `"`)
+
+	numeric := field == "likes" || field == "views"
+	if numeric != (op != ":") {
+		return nil, filterError("%s does not support %q", field, op)
+	}
+
+	switch field {
+	case "tag", "tags.any":
+		return &domain.BlogFilter{Tags: &domain.TagCondition{Any: splitList(value)}}, nil
+	case "tags.all":
+		return &domain.BlogFilter{Tags: &domain.TagCondition{All: splitList(value)}}, nil
+	case "tags.none":
+		return &domain.BlogFilter{Tags: &domain.TagCondition{None: splitList(value)}}, nil
+	case "author":
+		var ids []primitive.ObjectID
+		for _, v := range splitList(value) {
+			id, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				return nil, filterError("invalid author id %q", v)
+			}
+			ids = append(ids, id)
+		}
+		return &domain.BlogFilter{Authors: ids}, nil
+	case "likes", "views":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, filterError("%s needs a number", field)
+		}
+		if op == ">" {
+			n++
+		}
+		if field == "likes" {
+			return &domain.BlogFilter{MinLikes: &n}, nil
+		}
+		return &domain.BlogFilter{MinViews: &n}, nil
+	case "status":
+		return &domain.BlogFilter{Status: value}, nil
+	case "text":
+		return &domain.BlogFilter{Text: value}, nil
+	case "from", "to":
+		t, err := parseFilterDate(value, field == "to")
+		if err != nil {
+			return nil, err
+		}
+		if field == "from" {
+			return &domain.BlogFilter{From: &t}, nil
+		}
+		return &domain.BlogFilter{To: &t}, nil
+	}
+	return nil, filterError("unknown field %q", field)
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseFilterDate accepts RFC 3339 or a plain date; a plain "to" date includes the whole day
+func parseFilterDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, filterError("invalid date %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// ValidateBlogFilter checks a filter tree from either syntax before it reaches the repository
+func ValidateBlogFilter(f *domain.BlogFilter) error {
+	conditions, nodes := 0, 0
+	return validateFilterNode(f, 1, &conditions, &nodes)
+}
+
+func validateFilterNode(f *domain.BlogFilter, depth int, conditions, nodes *int) error {
+	if f == nil {
+		return nil
+	}
+	if depth > maxFilterDepth {
+		return filterError("filters may nest at most %d levels", maxFilterDepth)
+	}
+	if *nodes++; *nodes > maxFilterNodes {
+		return filterError("filters may have at most %d nodes", maxFilterNodes)
+	}
+
+	count := func(n int) error {
+		*conditions += n
+		if *conditions > maxFilterConditions {
+			return filterError("filters may have at most %d conditions", maxFilterConditions)
+		}
+		return nil
+	}
+
+	if f.Tags != nil {
+		for _, list := range [][]string{f.Tags.Any, f.Tags.All, f.Tags.None} {
+			if len(list) > maxFilterValues {
+				return filterError("at most %d tags per condition", maxFilterValues)
+			}
+		}
+		if err := count(1); err != nil {
+			return err
+		}
+	}
+	if len(f.Authors) > maxFilterValues {
+		return filterError("at most %d authors per condition", maxFilterValues)
+	}
+	if (f.MinLikes != nil && *f.MinLikes < 0) || (f.MinViews != nil && *f.MinViews < 0) {
+		return filterError("minimum likes and views cannot be negative")
+	}
+	switch f.Status {
+	case "", domain.BlogStatusPublished, domain.BlogStatusHidden:
+	default:
+		return filterError("status must be %s or %s", domain.BlogStatusPublished, domain.BlogStatusHidden)
+	}
+	if len(f.Text) > maxFilterTextLength {
+		return filterError("text is limited to %d characters", maxFilterTextLength)
+	}
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return filterError("from must be before to")
+	}
+
+	leaves := 0
+	for _, set := range []bool{len(f.Authors) > 0, f.MinLikes != nil, f.MinViews != nil, f.Status != "", f.Text != "", f.From != nil || f.To != nil} {
+		if set {
+			leaves++
+		}
+	}
+	if err := count(leaves); err != nil {
+		return err
+	}
+
+	for _, child := range append(append([]*domain.BlogFilter{}, f.And...), f.Or...) {
+		if err := validateFilterNode(child, depth+1, conditions, nodes); err != nil {
+			return err
+		}
+	}
+	return validateFilterNode(f.Not, depth+1, conditions, nodes)
+}
+
+// filterUsesStatus reports whether any node of the tree filters on status
+func filterUsesStatus(f *domain.BlogFilter) bool {
+	if f == nil {
+		return false
+	}
+	if f.Status != "" || filterUsesStatus(f.Not) {
+		return true
+	}
+	for _, child := range append(append([]*domain.BlogFilter{}, f.And...), f.Or...) {
+		if filterUsesStatus(child) {
+			return true
+		}
+	}
+	return false
+}
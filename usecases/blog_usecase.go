@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"Blog/domain"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -10,43 +11,97 @@ import (
 // struct to implement usecase
 type BlogUsecase struct {
 	repo domain.BlogRepository
+	userRepo domain.UserRepository
 	aiService domain.AIService
+	search domain.SearchEngine
+	events domain.EventBus
+	views *ViewTracker
+	mediaRepo domain.MediaRepository
 }
 
 // constructor for BlogUsecase
-func NewBlogUsecase(repo domain.BlogRepository,  aiService domain.AIService) *BlogUsecase {
-	return &BlogUsecase{repo: repo, aiService: aiService}
+func NewBlogUsecase(repo domain.BlogRepository, userRepo domain.UserRepository, aiService domain.AIService, search domain.SearchEngine, events domain.EventBus, views *ViewTracker, mediaRepo domain.MediaRepository) *BlogUsecase {
+	return &BlogUsecase{repo: repo, userRepo: userRepo, aiService: aiService, search: search, events: events, views: views, mediaRepo: mediaRepo}
 }
 
 
 // create blog
 func(u *BlogUsecase) CreateBlog(blog *domain.Blog) (*domain.Blog, error) {
-	return u.repo.CreateBlog(blog)
+	// keep the author's name on the post so search can match it
+	if user, err := u.userRepo.GetByID(blog.UserID); err == nil {
+		blog.AuthorName = user.Username
+	}
+	if err := checkBlogMedia(u.mediaRepo, blog.UserID, blog.MediaIDs); err != nil {
+		return nil, err
+	}
+	// new posts sort as unscored until the trending job reaches them
+	blog.Trending = &domain.TrendingScores{UpdatedAt: time.Now()}
+
+	created, err := u.repo.CreateBlog(blog)
+	if err != nil {
+		return nil, err
+	}
+
+	publish(u.events, domain.Event{Type: domain.EventBlogCreated, ActorID: created.UserID, BlogID: created.ID, Blog: created})
+	return created, nil
 }
 
-// retrive a blog using its id
-func (u *BlogUsecase) GetBlogByID(id string) (*domain.Blog, error) {
+// retrive a blog using its id, counting the read for the viewer
+func (u *BlogUsecase) GetBlogByID(id string, viewer domain.Viewer) (*domain.Blog, error) {
 	ObjId, err := primitive.ObjectIDFromHex(id)
 
 	if err != nil {
 		return nil, err
 	}
-	return u.repo.GetBlogByID(ObjId)
+
+	blog, err := u.repo.GetBlogByID(ObjId)
+	if err != nil {
+		return nil, err
+	}
+
+	// hidden posts are invisible to readers
+	if blog.Status == domain.BlogStatusHidden {
+		return nil, errors.New("blog not found")
+	}
+
+	if u.views != nil && u.views.Record(ObjId, viewer) {
+		publish(u.events, domain.Event{Type: domain.EventBlogViewed, BlogID: ObjId, Payload: viewer})
+	}
+
+	return blog, nil
 }
 
 // retrive all blogs
-func (u *BlogUsecase) GetAllBlogs() ([]*domain.Blog, error){
-	return u.repo.GetAllBlogs()
+func (u *BlogUsecase) GetAllBlogs(page domain.PageRequest) (*domain.Page[*domain.Blog], error){
+	return u.repo.GetAllBlogs(page.Normalize())
 }
 
 // update a blog using its id
-func ( u *BlogUsecase) UpdateBlog(id string, blog *domain.Blog) (*domain.Blog, error) {
+func ( u *BlogUsecase) UpdateBlog(id string, update domain.BlogUpdate) (*domain.Blog, error) {
 	ObjId, err := primitive.ObjectIDFromHex(id)
 
 	if err != nil {
 		return nil, err
 	}
-	return u.repo.UpdateBlog(ObjId, blog)
+
+	// attached media must come from the author's library
+	if update.MediaIDs != nil && len(*update.MediaIDs) > 0 {
+		stored, err := u.repo.GetBlogByID(ObjId)
+		if err != nil {
+			return nil, errors.New("blog not found")
+		}
+		if err := checkBlogMedia(u.mediaRepo, stored.UserID, *update.MediaIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := u.repo.UpdateBlog(ObjId, update)
+	if err != nil {
+		return nil, err
+	}
+
+	publish(u.events, domain.Event{Type: domain.EventBlogUpdated, ActorID: updated.UserID, BlogID: ObjId, Blog: updated})
+	return updated, nil
 }
 
 // delete a blog using its id
@@ -56,7 +111,13 @@ func ( u *BlogUsecase) DeleteBlog(id string) error {
 	if err != nil {
 		return err
 	}
-	return u.repo.DeleteBlog(ObjId)
+
+	if err := u.repo.DeleteBlog(ObjId); err != nil {
+		return err
+	}
+
+	publish(u.events, domain.Event{Type: domain.EventBlogDeleted, BlogID: ObjId})
+	return nil
 }
 
 
@@ -70,7 +131,14 @@ func (u *BlogUsecase) LikeBlog(userID, blogID string) error {
 	if err != nil {
 		return err
 	}
-	return u.repo.LikeBlog(uid, bid)
+	previous, err := react(u.repo, u.events, uid, bid, domain.ReactionLike)
+	if err != nil {
+		return err
+	}
+	if previous == domain.ReactionLike {
+		return errors.New("user already liked this blog")
+	}
+	return nil
 }
 
 func (u *BlogUsecase) DislikeBlog(userID, blogID string) error {
@@ -82,18 +150,153 @@ func (u *BlogUsecase) DislikeBlog(userID, blogID string) error {
 	if err != nil {
 		return err
 	}
-	return u.repo.DislikeBlog(uid, bid)
+	previous, err := react(u.repo, u.events, uid, bid, domain.ReactionDislike)
+	if err != nil {
+		return err
+	}
+	if previous == domain.ReactionDislike {
+		return errors.New("user already disliked this blog")
+	}
+	return nil
+}
+
+// UnlikeBlog withdraws the user's like
+func (u *BlogUsecase) UnlikeBlog(userID, blogID string) error {
+	return u.withdraw(userID, blogID, domain.ReactionLike)
+}
+
+// UndislikeBlog withdraws the user's dislike
+func (u *BlogUsecase) UndislikeBlog(userID, blogID string) error {
+	return u.withdraw(userID, blogID, domain.ReactionDislike)
+}
+
+func (u *BlogUsecase) withdraw(userID, blogID, reaction string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return err
+	}
+	return unreact(u.repo, u.events, uid, bid, reaction)
+}
+
+// FilterBlogs validates a filter tree and returns a page of matches with facet counts.
+// Only admins may filter on status, which is also what lets them see hidden posts.
+func (u *BlogUsecase) FilterBlogs(viewerID string, filter *domain.BlogFilter, sortBy string, page domain.PageRequest) (*domain.BlogFilterResult, error) {
+	if filter == nil {
+		filter = &domain.BlogFilter{}
+	}
+	if err := ValidateBlogFilter(filter); err != nil {
+		return nil, err
+	}
+
+	includeHidden := false
+	if filterUsesStatus(filter) {
+		if !u.isAdmin(viewerID) {
+			return nil, domain.ErrForbidden
+		}
+		includeHidden = true
+	}
+
+	return u.repo.FilterBlogs(filter, sortBy, page.Normalize(), includeHidden)
+}
+
+// GetTrending lists posts from the last day, week or month by their trending score
+func (u *BlogUsecase) GetTrending(window string, page domain.PageRequest) (*domain.Page[*domain.Blog], error) {
+	if window == "" {
+		window = domain.TrendingDay
+	}
+	w, ok := trendingWindows[window]
+	if !ok {
+		return nil, domain.ErrInvalidTrendingWindow
+	}
+	return u.repo.GetTrending(window, time.Now().Add(-w.span), page.Normalize())
+}
+
+// helper to check whether a (possibly anonymous) viewer is an admin
+func (u *BlogUsecase) isAdmin(userID string) bool {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false
+	}
+	user, err := u.userRepo.GetByID(uid)
+	return err == nil && user.Role == "admin"
 }
 
-// FilterBlogs accepts optional dates (send nil to ignore)
-func (u *BlogUsecase) FilterBlogs(tags []string, start, end *time.Time, sortBy string) ([]*domain.Blog, error) {
-	return u.repo.FilterBlogs(tags, start, end, sortBy)
+func (u *BlogUsecase) SuggestContent(userID, prompt string) (string, error) {
+    suggestion, err := u.aiService.Suggest(prompt)
+    uid, _ := primitive.ObjectIDFromHex(userID)
+    publish(u.events, domain.Event{Type: domain.EventAISuggestion, ActorID: uid, Payload: err != nil})
+    return suggestion, err
 }
 
-func (u *BlogUsecase) SuggestContent(prompt string) (string, error) {
-    return u.aiService.Suggest(prompt)
+// SearchBlogs ranks posts by relevance to a free-text query and highlights the matches
+func (u *BlogUsecase) SearchBlogs(query string, page domain.PageRequest) (*domain.Page[*domain.SearchHit], error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, domain.ErrEmptySearchQuery
+	}
+
+	hits, err := u.search.Search(terms, page.Normalize())
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range hits.Items {
+		hit.Highlights = buildHighlights(hit.Blog, terms)
+	}
+	return hits, nil
 }
 
-func (u *BlogUsecase) SearchBlog(title string) (*domain.Blog, error) {
-    return u.repo.SearchBlog(title)
+// SuggestSearch completes a partial word for search-as-you-type
+func (u *BlogUsecase) SuggestSearch(prefix string, limit int) ([]string, error) {
+	terms := searchTerms(prefix)
+	if len(terms) == 0 {
+		return []string{}, nil
+	}
+	if limit <= 0 || limit > 25 {
+		limit = 10
+	}
+	return u.search.Suggest(terms[len(terms)-1], limit)
+}
+
+// RebuildSearchIndex feeds every visible blog to the search engine, e.g. on startup
+func (u *BlogUsecase) RebuildSearchIndex() (int, error) {
+	count := 0
+	page := domain.PageRequest{Limit: domain.MaxPageSize}
+	for {
+		blogs, err := u.repo.GetAllBlogs(page)
+		if err != nil {
+			return count, err
+		}
+		for _, blog := range blogs.Items {
+			if err := u.search.Index(blog); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if !blogs.HasMore {
+			return count, nil
+		}
+		page.Cursor = blogs.NextCursor
+	}
+}
+
+// BackfillAuthorNames stores usernames on blogs created before they were denormalized for search
+func (u *BlogUsecase) BackfillAuthorNames() error {
+	ids, err := u.repo.GetAuthorsMissingName()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		user, err := u.userRepo.GetByID(id)
+		if err != nil {
+			continue
+		}
+		if err := u.repo.SetAuthorName(id, user.Username); err != nil {
+			return err
+		}
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,342 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxReadingListNameLength = 100
+
+// BookmarkUsecase saves posts for later, either as plain bookmarks or in named reading lists
+type BookmarkUsecase struct {
+	repo     domain.BookmarkRepository
+	blogRepo domain.BlogRepository
+}
+
+// constructor for BookmarkUsecase; deleted posts are dropped from bookmarks and lists
+func NewBookmarkUsecase(repo domain.BookmarkRepository, blogRepo domain.BlogRepository, events domain.EventBus) *BookmarkUsecase {
+	u := &BookmarkUsecase{repo: repo, blogRepo: blogRepo}
+	if events != nil {
+		events.Subscribe(domain.EventBlogDeleted, func(e domain.Event) {
+			if err := repo.RemoveBlog(e.BlogID); err != nil {
+				log.Printf("removing blog %s from bookmarks failed: %v", e.BlogID.Hex(), err)
+			}
+		})
+	}
+	return u
+}
+
+// AddBookmark saves a visible post for the user; saving it again is a no-op
+func (u *BookmarkUsecase) AddBookmark(userID, blogID string) error {
+	uid, bid, err := parseUserAndBlog(userID, blogID)
+	if err != nil {
+		return err
+	}
+	if err := u.requireVisible(bid); err != nil {
+		return err
+	}
+	return u.repo.AddBookmark(uid, bid)
+}
+
+func (u *BookmarkUsecase) RemoveBookmark(userID, blogID string) error {
+	uid, bid, err := parseUserAndBlog(userID, blogID)
+	if err != nil {
+		return err
+	}
+	return u.repo.RemoveBookmark(uid, bid)
+}
+
+// GetBookmarks lists the user's bookmarks newest first, with the posts that are still visible
+func (u *BookmarkUsecase) GetBookmarks(userID string, page domain.PageRequest) (*domain.Page[*domain.Bookmark], error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	bookmarks, err := u.repo.GetBookmarks(uid, page.Normalize())
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(bookmarks.Items))
+	for i, b := range bookmarks.Items {
+		ids[i] = b.BlogID
+	}
+	byID, err := u.loadBlogs(ids)
+	if err != nil {
+		return nil, err
+	}
+	bookmarked := true
+	for _, b := range bookmarks.Items {
+		if blog, ok := byID[b.BlogID]; ok {
+			blog.Bookmarked = &bookmarked
+			b.Blog = blog
+		}
+	}
+	return bookmarks, nil
+}
+
+// MarkBookmarked sets the bookmarked flag on blogs for a signed-in reader; anonymous readers get no flag
+func (u *BookmarkUsecase) MarkBookmarked(userID string, blogs ...*domain.Blog) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil || len(blogs) == 0 {
+		return
+	}
+	ids := make([]primitive.ObjectID, 0, len(blogs))
+	for _, b := range blogs {
+		if b != nil {
+			ids = append(ids, b.ID)
+		}
+	}
+	marked, err := u.repo.BookmarkedAmong(uid, ids)
+	if err != nil {
+		log.Println("loading bookmarks failed:", err)
+		return
+	}
+	for _, b := range blogs {
+		if b != nil {
+			bookmarked := marked[b.ID]
+			b.Bookmarked = &bookmarked
+		}
+	}
+}
+
+// CreateList starts a new, empty reading list
+func (u *BookmarkUsecase) CreateList(userID, name, description string, public bool) (*domain.ReadingList, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	name, err = listName(name)
+	if err != nil {
+		return nil, err
+	}
+	count, err := u.repo.CountLists(uid)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxReadingLists {
+		return nil, errors.New("too many reading lists")
+	}
+
+	now := time.Now()
+	return u.repo.CreateList(&domain.ReadingList{
+		UserID:      uid,
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Public:      public,
+		BlogIDs:     []primitive.ObjectID{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// GetLists returns the user's own reading lists, or only the public ones when viewed by someone else
+func (u *BookmarkUsecase) GetLists(viewerID, ownerID string) ([]*domain.ReadingList, error) {
+	oid, err := primitive.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.GetLists(oid, viewerID != ownerID)
+}
+
+// GetList returns a reading list with its posts in order. Private lists are only visible to their owner.
+func (u *BookmarkUsecase) GetList(viewerID, listID string) (*domain.ReadingList, error) {
+	list, err := u.findList(listID)
+	if err != nil {
+		return nil, err
+	}
+	if !list.Public && list.UserID.Hex() != viewerID {
+		// private lists are not acknowledged to exist
+		return nil, domain.ErrReadingListNotFound
+	}
+
+	byID, err := u.loadBlogs(list.BlogIDs)
+	if err != nil {
+		return nil, err
+	}
+	list.Blogs = []*domain.Blog{}
+	for _, id := range list.BlogIDs {
+		if blog, ok := byID[id]; ok {
+			list.Blogs = append(list.Blogs, blog)
+		}
+	}
+	u.MarkBookmarked(viewerID, list.Blogs...)
+	return list, nil
+}
+
+// UpdateList changes the name, description or visibility of a list; nil fields are left alone
+func (u *BookmarkUsecase) UpdateList(userID, listID string, name, description *string, public *bool) (*domain.ReadingList, error) {
+	list, err := u.ownedList(userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		if list.Name, err = listName(*name); err != nil {
+			return nil, err
+		}
+	}
+	if description != nil {
+		list.Description = strings.TrimSpace(*description)
+	}
+	if public != nil {
+		list.Public = *public
+	}
+	if err := u.repo.UpdateList(list); err != nil {
+		return nil, err
+	}
+	list.UpdatedAt = time.Now()
+	return list, nil
+}
+
+func (u *BookmarkUsecase) DeleteList(userID, listID string) error {
+	list, err := u.ownedList(userID, listID)
+	if err != nil {
+		return err
+	}
+	return u.repo.DeleteList(list.ID)
+}
+
+// AddToList inserts a visible post into a list at position, or at the end when position is negative
+func (u *BookmarkUsecase) AddToList(userID, listID, blogID string, position int) (*domain.ReadingList, error) {
+	list, err := u.ownedList(userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.requireVisible(bid); err != nil {
+		return nil, err
+	}
+	for _, id := range list.BlogIDs {
+		if id == bid {
+			return list, nil
+		}
+	}
+	if len(list.BlogIDs) >= domain.MaxReadingListItems {
+		return nil, errors.New("reading list is full")
+	}
+
+	if err := u.repo.AddToList(list.ID, bid, position); err != nil {
+		return nil, err
+	}
+	return u.repo.GetList(list.ID)
+}
+
+func (u *BookmarkUsecase) RemoveFromList(userID, listID, blogID string) (*domain.ReadingList, error) {
+	list, err := u.ownedList(userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.RemoveFromList(list.ID, bid); err != nil {
+		return nil, err
+	}
+	return u.repo.GetList(list.ID)
+}
+
+// ReorderList replaces the order of a list; the new order must be a permutation of the current one
+func (u *BookmarkUsecase) ReorderList(userID, listID string, blogIDs []string) (*domain.ReadingList, error) {
+	list, err := u.ownedList(userID, listID)
+	if err != nil {
+		return nil, err
+	}
+	if len(blogIDs) != len(list.BlogIDs) {
+		return nil, domain.ErrInvalidListOrder
+	}
+
+	current := make(map[primitive.ObjectID]bool, len(list.BlogIDs))
+	for _, id := range list.BlogIDs {
+		current[id] = true
+	}
+	order := make([]primitive.ObjectID, 0, len(blogIDs))
+	for _, raw := range blogIDs {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil || !current[id] {
+			return nil, domain.ErrInvalidListOrder
+		}
+		// each blog may appear only once
+		delete(current, id)
+		order = append(order, id)
+	}
+
+	list.BlogIDs = order
+	if err := u.repo.ReorderList(list.ID, order); err != nil {
+		return nil, err
+	}
+	list.UpdatedAt = time.Now()
+	return list, nil
+}
+
+func (u *BookmarkUsecase) findList(listID string) (*domain.ReadingList, error) {
+	lid, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return nil, domain.ErrReadingListNotFound
+	}
+	return u.repo.GetList(lid)
+}
+
+// ownedList loads a list that the user is about to change
+func (u *BookmarkUsecase) ownedList(userID, listID string) (*domain.ReadingList, error) {
+	list, err := u.findList(listID)
+	if err != nil {
+		return nil, err
+	}
+	if list.UserID.Hex() != userID {
+		return nil, domain.ErrForbidden
+	}
+	return list, nil
+}
+
+func (u *BookmarkUsecase) requireVisible(blogID primitive.ObjectID) error {
+	blog, err := u.blogRepo.GetBlogByID(blogID)
+	if err != nil || blog.Status == domain.BlogStatusHidden {
+		return errors.New("blog not found")
+	}
+	return nil
+}
+
+// loadBlogs fetches the visible posts among ids; deleted or hidden ones are left out
+func (u *BookmarkUsecase) loadBlogs(ids []primitive.ObjectID) (map[primitive.ObjectID]*domain.Blog, error) {
+	byID := map[primitive.ObjectID]*domain.Blog{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
+	blogs, err := u.blogRepo.GetBlogsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blogs {
+		byID[b.ID] = b
+	}
+	return byID, nil
+}
+
+func parseUserAndBlog(userID, blogID string) (primitive.ObjectID, primitive.ObjectID, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return uid, primitive.NilObjectID, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	return uid, bid, err
+}
+
+func listName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("list name is required")
+	}
+	if len([]rune(name)) > maxReadingListNameLength {
+		return "", errors.New("list name is too long")
+	}
+	return name, nil
+}
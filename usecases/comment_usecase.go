@@ -0,0 +1,261 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentUsecase struct {
+	repo     domain.CommentRepository
+	blogRepo domain.BlogRepository
+	userRepo domain.UserRepository
+	events   domain.EventBus
+}
+
+// constructor for CommentUsecase; comments of deleted posts are removed with them
+func NewCommentUsecase(repo domain.CommentRepository, blogRepo domain.BlogRepository, userRepo domain.UserRepository, events domain.EventBus) *CommentUsecase {
+	u := &CommentUsecase{repo: repo, blogRepo: blogRepo, userRepo: userRepo, events: events}
+	if events != nil {
+		events.Subscribe(domain.EventBlogDeleted, func(e domain.Event) {
+			if err := repo.DeleteByBlog(e.BlogID); err != nil {
+				log.Printf("removing comments of blog %s failed: %v", e.BlogID.Hex(), err)
+			}
+		})
+	}
+	return u
+}
+
+// moderation actions map to the resulting comment status
+var moderationActions = map[string]string{
+	"approve": domain.CommentStatusApproved,
+	"reject":  domain.CommentStatusRejected,
+	"spam":    domain.CommentStatusSpam,
+}
+
+// AddComment applies the blog's comment mode and the blocked-words list before saving.
+// parentID, when set, makes the comment a reply to an approved comment on the same blog.
+func (u *CommentUsecase) AddComment(userID, blogID, content, parentID string) (*domain.Comment, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("comment content is required")
+	}
+
+	blog, err := u.blogRepo.GetBlogByID(bid)
+	if err != nil {
+		return nil, errors.New("blog not found")
+	}
+
+	status := domain.CommentStatusApproved
+	switch blog.CommentMode {
+	case domain.CommentModeClosed:
+		return nil, domain.ErrCommentsClosed
+	case domain.CommentModeApproval:
+		status = domain.CommentStatusPending
+	}
+
+	var parent *primitive.ObjectID
+	if parentID != "" {
+		pid, err := primitive.ObjectIDFromHex(parentID)
+		if err != nil {
+			return nil, domain.ErrCommentNotFound
+		}
+		replyTo, err := u.repo.GetCommentByID(pid)
+		if err != nil || replyTo.BlogID != bid || replyTo.Status != domain.CommentStatusApproved {
+			return nil, domain.ErrCommentNotFound
+		}
+		parent = &pid
+	}
+
+	words, err := u.repo.GetBlockedWords()
+	if err != nil {
+		return nil, err
+	}
+	if containsBlockedWord(content, words) {
+		return nil, domain.ErrBlockedWords
+	}
+
+	comment := domain.Comment{
+		ID:           primitive.NewObjectID(),
+		BlogID:       bid,
+		BlogAuthorID: blog.UserID,
+		UserID:       uid,
+		ParentID:     parent,
+		Content:      content,
+		Status:       status,
+		CreatedAt:    time.Now(),
+	}
+	created, err := u.repo.CreateComment(&comment)
+	if err != nil {
+		return nil, err
+	}
+
+	publish(u.events, domain.Event{Type: domain.EventCommentCreated, ActorID: uid, BlogID: bid, Payload: created})
+	return created, nil
+}
+
+// GetComments returns the approved comments of a blog
+func (u *CommentUsecase) GetComments(blogID string) ([]*domain.Comment, error) {
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.GetCommentsByBlog(bid, domain.CommentStatusApproved)
+}
+
+// SetCommentMode lets the blog author (or an admin) open, close or require approval for comments
+func (u *CommentUsecase) SetCommentMode(userID, blogID, mode string) error {
+	switch mode {
+	case domain.CommentModeOpen, domain.CommentModeApproval, domain.CommentModeClosed:
+	default:
+		return errors.New("comment mode must be open, approval or closed")
+	}
+
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return err
+	}
+
+	blog, err := u.blogRepo.GetBlogByID(bid)
+	if err != nil {
+		return errors.New("blog not found")
+	}
+	if err := u.authorOrAdmin(uid, blog.UserID); err != nil {
+		return err
+	}
+	return u.blogRepo.SetCommentMode(bid, mode)
+}
+
+// GetModerationQueue returns every pending comment for admins and the pending comments on their own posts for authors
+func (u *CommentUsecase) GetModerationQueue(userID string) ([]*domain.Comment, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.userRepo.GetByID(uid)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Role == "admin" {
+		return u.repo.GetModerationQueue(nil)
+	}
+	return u.repo.GetModerationQueue(&uid)
+}
+
+// ModerateComment approves, rejects or marks a comment as spam
+func (u *CommentUsecase) ModerateComment(userID, commentID, action string) error {
+	status, ok := moderationActions[action]
+	if !ok {
+		return domain.ErrInvalidModAction
+	}
+
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	cid, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return err
+	}
+
+	comment, err := u.repo.GetCommentByID(cid)
+	if err != nil {
+		return err
+	}
+	if err := u.authorOrAdmin(uid, comment.BlogAuthorID); err != nil {
+		return err
+	}
+	if err := u.repo.UpdateCommentStatus(cid, status, uid); err != nil {
+		return err
+	}
+
+	if status == domain.CommentStatusApproved && comment.Status == domain.CommentStatusPending {
+		comment.Status = status
+		publish(u.events, domain.Event{Type: domain.EventCommentApproved, ActorID: uid, BlogID: comment.BlogID, Payload: comment})
+	}
+	return nil
+}
+
+func (u *CommentUsecase) GetBlockedWords() ([]string, error) {
+	return u.repo.GetBlockedWords()
+}
+
+// SetBlockedWords normalizes and stores the blocked-words list; entries of several words
+// block the phrase, whatever spacing and punctuation separate its words
+func (u *CommentUsecase) SetBlockedWords(words []string) error {
+	seen := map[string]bool{}
+	cleaned := []string{}
+	for _, w := range words {
+		w = strings.Join(blockedWordFields(w), " ")
+		if w == "" || seen[w] {
+			continue
+		}
+		seen[w] = true
+		cleaned = append(cleaned, w)
+	}
+	return u.repo.SetBlockedWords(cleaned)
+}
+
+// helper to allow only the owner of a resource or an admin
+func (u *CommentUsecase) authorOrAdmin(userID, authorID primitive.ObjectID) error {
+	if userID == authorID {
+		return nil
+	}
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.Role != "admin" {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// helper to match blocked words and phrases as whole words, case-insensitively
+func containsBlockedWord(content string, words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+	// entries by their first word, so each word of the content is looked up once
+	blocked := map[string][][]string{}
+	for _, w := range words {
+		if entry := blockedWordFields(w); len(entry) > 0 {
+			blocked[entry[0]] = append(blocked[entry[0]], entry)
+		}
+	}
+	fields := blockedWordFields(content)
+	for i, f := range fields {
+		for _, entry := range blocked[f] {
+			if i+len(entry) <= len(fields) && slices.Equal(fields[i:i+len(entry)], entry) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// helper to split text into the lowercase words blocked words are matched on
+func blockedWordFields(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+	})
+}
@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// periods reported on the dashboard and the size of its lists
+var dashboardPeriods = []struct {
+	name string
+	span time.Duration
+}{
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+const (
+	dashboardTopPosts       = 5
+	dashboardRecentComments = 10
+	followerGrowthDays      = 30
+)
+
+type DashboardUsecase struct {
+	repo domain.DashboardRepository
+}
+
+func NewDashboardUsecase(repo domain.DashboardRepository) *DashboardUsecase {
+	return &DashboardUsecase{repo: repo}
+}
+
+// GetDashboard gathers an author's post counts, interaction totals, top posts,
+// recent comments and follower growth
+func (u *DashboardUsecase) GetDashboard(userID string) (*domain.AuthorDashboard, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &domain.AuthorDashboard{}
+	if d.Posts, err = u.repo.CountPostsByStatus(uid); err != nil {
+		return nil, err
+	}
+	if d.Totals, err = u.repo.GetTotals(uid); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	today := truncateBucket(now, domain.GranularityDay)
+	for _, p := range dashboardPeriods {
+		since := now.Add(-p.span)
+		counts, err := u.repo.GetActivity(uid, since)
+		if err != nil {
+			return nil, err
+		}
+		d.Periods = append(d.Periods, domain.PeriodStats{Period: p.name, Since: since, StatCounts: counts})
+	}
+
+	if d.TopPosts, err = u.repo.GetTopPosts(uid, dashboardTopPosts); err != nil {
+		return nil, err
+	}
+	if d.RecentComments, err = u.repo.GetRecentComments(uid, dashboardRecentComments); err != nil {
+		return nil, err
+	}
+
+	start := today.AddDate(0, 0, -(followerGrowthDays - 1))
+	followers, err := u.repo.GetFollowerStats(uid, start)
+	if err != nil {
+		return nil, err
+	}
+	// one entry per day so charts need no gap filling
+	followers.Growth = fillDays(followers.Growth, start, today)
+	d.Followers = followers
+	return d, nil
+}
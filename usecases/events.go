@@ -0,0 +1,35 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"log"
+	"time"
+)
+
+// helper to publish an event when a bus is configured
+func publish(bus domain.EventBus, e domain.Event) {
+	if bus == nil {
+		return
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	bus.Publish(e)
+}
+
+// SyncSearchIndex keeps a search engine up to date with blog lifecycle events
+func SyncSearchIndex(bus domain.EventBus, engine domain.SearchEngine) {
+	index := func(e domain.Event) {
+		if err := engine.Index(e.Blog); err != nil {
+			log.Printf("search index update for blog %s failed: %v", e.BlogID.Hex(), err)
+		}
+	}
+	bus.Subscribe(domain.EventBlogCreated, index)
+	bus.Subscribe(domain.EventBlogUpdated, index)
+	bus.Subscribe(domain.EventBlogStatusChanged, index)
+	bus.Subscribe(domain.EventBlogDeleted, func(e domain.Event) {
+		if err := engine.Remove(e.BlogID); err != nil {
+			log.Printf("search index removal for blog %s failed: %v", e.BlogID.Hex(), err)
+		}
+	})
+}
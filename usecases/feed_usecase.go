@@ -0,0 +1,347 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"encoding/base64"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// how many recent posts are kept per popular author, and for how long
+const (
+	timelineSize     = 200
+	timelineCacheTTL = 10 * time.Minute
+	maxTagLength     = 50
+)
+
+// FeedUsecase manages who follows whom and assembles each reader's home feed.
+//
+// Feeds are built on read: posts by followed authors and tags are queried and merged by date.
+// Authors with many followers would otherwise be queried once per follower, so their recent
+// posts are kept in a shared timeline cache and merged in from memory.
+type FeedUsecase struct {
+	repo         domain.FollowRepository
+	blogRepo     domain.BlogRepository
+	userRepo     domain.UserRepository
+	events       domain.EventBus
+	minFollowers int
+
+	mu        sync.RWMutex
+	popular   map[primitive.ObjectID]bool
+	timelines *ttlCache[primitive.ObjectID, []*domain.Blog]
+}
+
+// constructor for FeedUsecase; authors with at least minFollowers followers get a cached timeline
+func NewFeedUsecase(repo domain.FollowRepository, blogRepo domain.BlogRepository, userRepo domain.UserRepository, events domain.EventBus, minFollowers int) *FeedUsecase {
+	u := &FeedUsecase{
+		repo:         repo,
+		blogRepo:     blogRepo,
+		userRepo:     userRepo,
+		events:       events,
+		minFollowers: minFollowers,
+		popular:      map[primitive.ObjectID]bool{},
+		timelines:    newTTLCache[primitive.ObjectID, []*domain.Blog](timelineCacheTTL),
+	}
+	if events != nil {
+		// a change to a post only affects its author's timeline
+		invalidate := func(e domain.Event) {
+			if e.Blog != nil {
+				u.timelines.Delete(e.Blog.UserID)
+			} else {
+				u.timelines.Clear()
+			}
+		}
+		events.Subscribe(domain.EventBlogCreated, invalidate)
+		events.Subscribe(domain.EventBlogUpdated, invalidate)
+		events.Subscribe(domain.EventBlogStatusChanged, invalidate)
+		events.Subscribe(domain.EventBlogDeleted, invalidate)
+	}
+	return u
+}
+
+// FollowAuthor subscribes the user to an author's posts
+func (u *FeedUsecase) FollowAuthor(userID, authorID string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	aid, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return err
+	}
+	if uid == aid {
+		return domain.ErrCannotFollowSelf
+	}
+	if _, err := u.userRepo.GetByID(aid); err != nil {
+		return errors.New("user not found")
+	}
+	if err := u.follow(&domain.Follow{FollowerID: uid, Type: domain.FollowAuthor, AuthorID: aid}); err != nil {
+		return err
+	}
+	publish(u.events, domain.Event{Type: domain.EventAuthorFollowed, ActorID: uid, Payload: aid})
+	return nil
+}
+
+func (u *FeedUsecase) UnfollowAuthor(userID, authorID string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	aid, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return err
+	}
+	return u.repo.Unfollow(&domain.Follow{FollowerID: uid, Type: domain.FollowAuthor, AuthorID: aid})
+}
+
+// FollowTag subscribes the user to every post carrying a tag
+func (u *FeedUsecase) FollowTag(userID, tag string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	tag, err = followTag(tag)
+	if err != nil {
+		return err
+	}
+	return u.follow(&domain.Follow{FollowerID: uid, Type: domain.FollowTag, Tag: tag})
+}
+
+func (u *FeedUsecase) UnfollowTag(userID, tag string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	tag, err = followTag(tag)
+	if err != nil {
+		return err
+	}
+	return u.repo.Unfollow(&domain.Follow{FollowerID: uid, Type: domain.FollowTag, Tag: tag})
+}
+
+func (u *FeedUsecase) follow(f *domain.Follow) error {
+	count, err := u.repo.CountFollowing(f.FollowerID)
+	if err != nil {
+		return err
+	}
+	if count >= domain.MaxFollows {
+		return domain.ErrTooManyFollows
+	}
+	f.CreatedAt = time.Now()
+	return u.repo.Follow(f)
+}
+
+// GetFollowing lists the authors and tags the user follows
+func (u *FeedUsecase) GetFollowing(userID string) ([]*domain.Follow, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.GetFollowing(uid)
+}
+
+func (u *FeedUsecase) CountFollowers(authorID string) (int, error) {
+	aid, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return 0, err
+	}
+	return u.repo.CountFollowers(aid)
+}
+
+// GetFeed returns a page of visible posts from followed authors and tags, newest first
+func (u *FeedUsecase) GetFeed(userID string, page domain.PageRequest) (*domain.Page[*domain.Blog], error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	page = page.Normalize()
+	before, beforeID, err := decodeFeedCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	follows, err := u.repo.GetFollowing(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	// one extra post tells whether another page exists
+	want := page.Limit + 1
+	query := domain.FeedQuery{Before: before, BeforeID: beforeID, Limit: want}
+	var merged []*domain.Blog
+	for _, f := range follows {
+		if f.Type == domain.FollowTag {
+			query.Tags = append(query.Tags, f.Tag)
+			continue
+		}
+		if cached, ok := u.cachedTimeline(f.AuthorID, before, beforeID, want); ok {
+			merged = append(merged, cached...)
+		} else {
+			query.AuthorIDs = append(query.AuthorIDs, f.AuthorID)
+		}
+	}
+
+	queried, err := u.blogRepo.GetFeed(query)
+	if err != nil {
+		return nil, err
+	}
+	merged = append(merged, queried...)
+
+	// a post can arrive through both its author and its tags
+	seen := make(map[primitive.ObjectID]bool, len(merged))
+	items := merged[:0]
+	for _, b := range merged {
+		if !seen[b.ID] {
+			seen[b.ID] = true
+			items = append(items, b)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return feedBefore(items[i], items[j]) })
+
+	result := &domain.Page[*domain.Blog]{Items: items, Limit: page.Limit}
+	if len(items) > page.Limit {
+		result.Items = items[:page.Limit]
+		result.HasMore = true
+		last := result.Items[page.Limit-1]
+		result.NextCursor = encodeFeedCursor(last.DateCreated, last.ID)
+	}
+	return result, nil
+}
+
+// cachedTimeline returns a popular author's posts after the cursor from the shared timeline.
+// It declines when the author is not popular or the cursor has paged past the cached window,
+// in which case the author is queried like any other.
+func (u *FeedUsecase) cachedTimeline(authorID primitive.ObjectID, before time.Time, beforeID primitive.ObjectID, want int) ([]*domain.Blog, bool) {
+	u.mu.RLock()
+	popular := u.popular[authorID]
+	u.mu.RUnlock()
+	if !popular {
+		return nil, false
+	}
+
+	timeline, ok := u.timelines.Get(authorID)
+	if !ok {
+		loaded, err := u.blogRepo.GetFeed(domain.FeedQuery{AuthorIDs: []primitive.ObjectID{authorID}, Limit: timelineSize})
+		if err != nil {
+			log.Printf("loading timeline of author %s failed: %v", authorID.Hex(), err)
+			return nil, false
+		}
+		timeline = loaded
+		u.timelines.Set(authorID, timeline)
+	}
+
+	start := 0
+	if !before.IsZero() {
+		cursor := &domain.Blog{ID: beforeID, DateCreated: before}
+		start = sort.Search(len(timeline), func(i int) bool { return feedBefore(cursor, timeline[i]) })
+	}
+	rest := timeline[start:]
+	// a full timeline may have older posts that were not cached
+	if len(timeline) == timelineSize && len(rest) < want {
+		return nil, false
+	}
+	if len(rest) > want {
+		rest = rest[:want]
+	}
+	// the cached posts are shared, and responses get annotated per reader
+	copies := make([]*domain.Blog, len(rest))
+	for i, b := range rest {
+		c := *b
+		copies[i] = &c
+	}
+	return copies, true
+}
+
+// RefreshPopularAuthors recomputes which authors are served from the timeline cache
+func (u *FeedUsecase) RefreshPopularAuthors() error {
+	authors, err := u.repo.GetPopularAuthors(u.minFollowers)
+	if err != nil {
+		return err
+	}
+	popular := make(map[primitive.ObjectID]bool, len(authors))
+	for _, id := range authors {
+		popular[id] = true
+	}
+
+	u.mu.Lock()
+	for id := range u.popular {
+		if !popular[id] {
+			u.timelines.Delete(id)
+		}
+	}
+	u.popular = popular
+	u.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the popular authors every interval until stop is closed
+func (u *FeedUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := u.RefreshPopularAuthors(); err != nil {
+			log.Println("popular author refresh failed:", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// feedBefore orders posts newest first, breaking ties on id like the blog "date" sort
+func feedBefore(a, b *domain.Blog) bool {
+	if !a.DateCreated.Equal(b.DateCreated) {
+		return a.DateCreated.After(b.DateCreated)
+	}
+	return a.ID.Hex() > b.ID.Hex()
+}
+
+// feed cursors are "<unix millis>.<last blog id>"; Mongo stores dates to the millisecond
+func encodeFeedCursor(t time.Time, id primitive.ObjectID) string {
+	raw := strconv.FormatInt(t.UnixMilli(), 10) + "." + id.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeFeedCursor(cursor string) (time.Time, primitive.ObjectID, error) {
+	if cursor == "" {
+		return time.Time{}, primitive.NilObjectID, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, domain.ErrInvalidCursor
+	}
+	millis, hex, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return time.Time{}, primitive.NilObjectID, domain.ErrInvalidCursor
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, domain.ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, domain.ErrInvalidCursor
+	}
+	return time.UnixMilli(ms), id, nil
+}
+
+func followTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", errors.New("tag is required")
+	}
+	if len([]rune(tag)) > maxTagLength {
+		return "", errors.New("tag is too long")
+	}
+	return tag, nil
+}
@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// snippets show this many words around the densest cluster of matches
+const snippetWords = 30
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// maximum number of terms taken from a search query
+const maxSearchTerms = 16
+
+// searchTerms reduces free text to plain lowercase words, which also strips any
+// operator syntax ($text quotes and negation, regex metacharacters) from user input
+func searchTerms(query string) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// buildHighlights marks query terms in the title and picks the best matching excerpt of the content
+func buildHighlights(blog *domain.Blog, terms []string) *domain.Highlights {
+	titleSpans := wordPattern.FindAllStringIndex(blog.Title, -1)
+	title := markWords(blog.Title, titleSpans, 0, len(titleSpans), terms)
+
+	spans := wordPattern.FindAllStringIndex(blog.Content, -1)
+	if len(spans) == 0 {
+		return &domain.Highlights{Title: title}
+	}
+
+	// slide a window over the words and keep the one with the most matches
+	best, bestCount, count := 0, -1, 0
+	for i := range spans {
+		if termMatches(blog.Content[spans[i][0]:spans[i][1]], terms) {
+			count++
+		}
+		if i >= snippetWords && termMatches(blog.Content[spans[i-snippetWords][0]:spans[i-snippetWords][1]], terms) {
+			count--
+		}
+		start := i - snippetWords + 1
+		if start < 0 {
+			start = 0
+		}
+		if count > bestCount {
+			best, bestCount = start, count
+		}
+	}
+
+	// centre the window on the matches it contains
+	first, last := -1, -1
+	for i := best; i < best+snippetWords && i < len(spans); i++ {
+		if termMatches(blog.Content[spans[i][0]:spans[i][1]], terms) {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first >= 0 {
+		best = (first+last)/2 - snippetWords/2
+	}
+	if best > len(spans)-snippetWords {
+		best = len(spans) - snippetWords
+	}
+	if best < 0 {
+		best = 0
+	}
+	end := best + snippetWords
+	if end > len(spans) {
+		end = len(spans)
+	}
+
+	snippet := markWords(blog.Content, spans, best, end, terms)
+	if best > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(spans) {
+		snippet += "…"
+	}
+	return &domain.Highlights{Title: title, Snippet: snippet}
+}
+
+// markWords escapes text[spans[from][0]:spans[to-1][1]] and wraps matching words in <mark>
+func markWords(text string, spans [][]int, from, to int, terms []string) string {
+	if from >= to {
+		return html.EscapeString(text)
+	}
+	var b strings.Builder
+	pos := spans[from][0]
+	for _, span := range spans[from:to] {
+		b.WriteString(html.EscapeString(text[pos:span[0]]))
+		word := text[span[0]:span[1]]
+		if termMatches(word, terms) {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		pos = span[1]
+	}
+	return b.String()
+}
+
+// termMatches is a loose stand-in for stemming: "run" matches "running" and "runs"
+func termMatches(word string, terms []string) bool {
+	word = strings.ToLower(word)
+	for _, t := range terms {
+		if strings.HasPrefix(word, t) || (len(word) >= 3 && strings.HasPrefix(t, word)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,473 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registered for DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	_ "golang.org/x/image/webp"
+)
+
+// longest original filename kept
+const maxMediaFilename = 200
+
+// how many uploads may wait for a processing worker; more are picked up on the next start
+const mediaQueueSize = 1000
+
+// where uploads wait for processing, under an unguessable name so they cannot be fetched
+const stagingPrefix = "staging/"
+
+// MediaUsecase manages per-user media libraries; files go to a pluggable MediaStorage
+// and posts reference them by id
+type MediaUsecase struct {
+	repo     domain.MediaRepository
+	blogRepo domain.BlogRepository
+	storage  domain.MediaStorage
+	images   domain.ImageProcessor
+	maxSize  int64 // largest accepted file
+	quota    int64 // most bytes one library may hold, 0 for no limit
+	jobs     chan primitive.ObjectID
+}
+
+// constructor for MediaUsecase; sizes are in bytes. Images the processor supports are
+// only published once RunWorkers has stripped their metadata and rendered their variants.
+func NewMediaUsecase(repo domain.MediaRepository, blogRepo domain.BlogRepository, storage domain.MediaStorage, images domain.ImageProcessor, maxSize, quota int64) *MediaUsecase {
+	return &MediaUsecase{
+		repo:     repo,
+		blogRepo: blogRepo,
+		storage:  storage,
+		images:   images,
+		maxSize:  maxSize,
+		quota:    quota,
+		jobs:     make(chan primitive.ObjectID, mediaQueueSize),
+	}
+}
+
+// MaxUploadSize is the largest file Upload accepts, in bytes
+func (u *MediaUsecase) MaxUploadSize() int64 {
+	return u.maxSize
+}
+
+// Upload adds a file to the user's media library. The type is sniffed from the content
+// rather than trusted from the client, and images must decode. Images that get processed
+// are staged and come back with status "processing" and no URL yet.
+func (u *MediaUsecase) Upload(userID, filename string, body io.Reader) (*domain.Media, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, u.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > u.maxSize {
+		return nil, domain.ErrMediaTooLarge
+	}
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if u.quota > 0 {
+		used, err := u.repo.TotalSize(uid)
+		if err != nil {
+			return nil, err
+		}
+		if used+int64(len(data)) > u.quota {
+			return nil, domain.ErrMediaQuotaExceeded
+		}
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	ext, ok := domain.MediaTypes[contentType]
+	if !ok {
+		return nil, domain.ErrUnsupportedMediaType
+	}
+
+	id := primitive.NewObjectID()
+	media := &domain.Media{
+		ID:          id,
+		UserID:      uid,
+		Key:         uid.Hex() + "/" + id.Hex() + ext,
+		Filename:    mediaFilename(filename, ext),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now(),
+	}
+	if strings.HasPrefix(contentType, "image/") {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: the image could not be read", domain.ErrUnsupportedMediaType)
+		}
+		if config.Width*config.Height > domain.MaxImagePixels {
+			return nil, fmt.Errorf("%w: images may have at most %d megapixels", domain.ErrMediaTooLarge, domain.MaxImagePixels/1_000_000)
+		}
+		media.Width, media.Height = config.Width, config.Height
+	}
+
+	key := media.Key
+	media.Status = domain.MediaReady
+	if u.images != nil && u.images.Supports(contentType) {
+		media.Status = domain.MediaProcessing
+		media.StagingKey = stagingPrefix + randomToken() + ext
+		key = media.StagingKey
+	}
+	if err := u.storage.Put(key, bytes.NewReader(data), media.Size, contentType); err != nil {
+		return nil, err
+	}
+	if err := u.repo.CreateMedia(media); err != nil {
+		if err := u.storage.Delete(key); err != nil {
+			log.Printf("removing orphaned media %s failed: %v", key, err)
+		}
+		return nil, err
+	}
+	if media.Status == domain.MediaProcessing {
+		u.enqueue(media.ID)
+	}
+	u.withURLs(media)
+	return media, nil
+}
+
+// GetMedia returns one library entry; files are public once uploaded
+func (u *MediaUsecase) GetMedia(mediaID string) (*domain.Media, error) {
+	mid, err := primitive.ObjectIDFromHex(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	media, err := u.repo.GetMedia(mid)
+	if err != nil {
+		return nil, err
+	}
+	u.withURLs(media)
+	return media, nil
+}
+
+// GetLibrary lists the user's uploads, newest first
+func (u *MediaUsecase) GetLibrary(userID string, page domain.PageRequest) (*domain.Page[*domain.Media], error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	library, err := u.repo.GetUserMedia(uid, page)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range library.Items {
+		u.withURLs(m)
+	}
+	return library, nil
+}
+
+// DeleteMedia removes a file from its owner's library and from every post using it
+func (u *MediaUsecase) DeleteMedia(userID, mediaID string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	mid, err := primitive.ObjectIDFromHex(mediaID)
+	if err != nil {
+		return err
+	}
+	media, err := u.repo.GetMedia(mid)
+	if err != nil {
+		return err
+	}
+	if media.UserID != uid {
+		return domain.ErrForbidden
+	}
+
+	if err := u.repo.DeleteMedia(mid); err != nil {
+		return err
+	}
+	if err := u.blogRepo.RemoveMedia(mid); err != nil {
+		return err
+	}
+	return u.deleteFiles(media)
+}
+
+// deleteFiles removes every stored object belonging to media
+func (u *MediaUsecase) deleteFiles(media *domain.Media) error {
+	keys := []string{media.Key}
+	if media.StagingKey != "" {
+		keys = append(keys, media.StagingKey)
+	}
+	for _, v := range media.Variants {
+		keys = append(keys, v.Key)
+	}
+	var errs []error
+	for _, key := range keys {
+		if err := u.storage.Delete(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Open streams a stored file with its content type, for storage that is not served directly
+func (u *MediaUsecase) Open(key string) (io.ReadCloser, string, error) {
+	if strings.HasPrefix(path.Clean("/"+key), "/"+stagingPrefix) {
+		return nil, "", domain.ErrMediaNotFound
+	}
+	contentType := ""
+	for t, ext := range domain.MediaTypes {
+		if path.Ext(key) == ext {
+			contentType = t
+		}
+	}
+	if contentType == "" {
+		return nil, "", domain.ErrMediaNotFound
+	}
+	body, err := u.storage.Open(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, contentType, nil
+}
+
+// AttachMedia resolves the media posts reference, keeping each post's order
+func (u *MediaUsecase) AttachMedia(blogs ...*domain.Blog) {
+	var ids []primitive.ObjectID
+	for _, b := range blogs {
+		if b != nil {
+			ids = append(ids, b.MediaIDs...)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	media, err := u.repo.GetMediaByIDs(ids)
+	if err != nil {
+		log.Println("loading blog media failed:", err)
+		return
+	}
+	byID := make(map[primitive.ObjectID]*domain.Media, len(media))
+	for _, m := range media {
+		u.withURLs(m)
+		byID[m.ID] = m
+	}
+	for _, b := range blogs {
+		if b == nil {
+			continue
+		}
+		b.Media = []*domain.Media{}
+		for _, id := range b.MediaIDs {
+			if m, ok := byID[id]; ok {
+				b.Media = append(b.Media, m)
+			}
+		}
+	}
+}
+
+// withURLs fills in where clients fetch a file and its variants, once it is ready
+func (u *MediaUsecase) withURLs(media *domain.Media) {
+	if media.Status == "" {
+		// uploaded before images were processed
+		media.Status = domain.MediaReady
+	}
+	if media.Status != domain.MediaReady {
+		return
+	}
+	media.URL = u.storage.URL(media.Key)
+	for _, v := range media.Variants {
+		v.URL = u.storage.URL(v.Key)
+	}
+}
+
+// enqueue hands an upload to the processing workers without blocking the request;
+// when the queue is full it stays pending until the next start
+func (u *MediaUsecase) enqueue(id primitive.ObjectID) {
+	select {
+	case u.jobs <- id:
+	default:
+		log.Printf("media processing queue is full, %s waits for the next start", id.Hex())
+	}
+}
+
+// RunWorkers processes uploaded images with the given number of workers until stop is
+// closed, first queueing the uploads a previous run left unprocessed
+func (u *MediaUsecase) RunWorkers(workers int, stop <-chan struct{}) {
+	pending, err := u.repo.GetPendingMedia(mediaQueueSize)
+	if err != nil {
+		log.Println("loading pending media failed:", err)
+	}
+	for _, m := range pending {
+		u.enqueue(m.ID)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case id := <-u.jobs:
+					if err := u.process(id); err != nil {
+						log.Printf("processing media %s failed: %v", id.Hex(), err)
+					}
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// process strips a staged image's metadata, stores it with its variants and publishes it.
+// Images that cannot be processed are marked failed and never published.
+func (u *MediaUsecase) process(id primitive.ObjectID) error {
+	media, err := u.repo.GetMedia(id)
+	if errors.Is(err, domain.ErrMediaNotFound) {
+		return nil // deleted while queued, along with its staged file
+	}
+	if err != nil {
+		return err
+	}
+	if media.Status != domain.MediaProcessing {
+		return nil
+	}
+
+	result, err := u.processStaged(media)
+	if err != nil {
+		media.Status = domain.MediaFailed
+		if err := u.storage.Delete(media.StagingKey); err != nil {
+			log.Printf("removing staged media %s failed: %v", media.StagingKey, err)
+		}
+		media.StagingKey = ""
+		if updateErr := u.repo.UpdateMedia(media); updateErr != nil && !errors.Is(updateErr, domain.ErrMediaNotFound) {
+			return errors.Join(err, updateErr)
+		}
+		return err
+	}
+
+	staged := media.StagingKey
+	media.Status = domain.MediaReady
+	media.StagingKey = ""
+	media.Size = result.size
+	media.Width, media.Height = result.Width, result.Height
+	media.Variants = result.variants
+	media.Blurhash = result.Blurhash
+	if err := u.repo.UpdateMedia(media); err != nil {
+		if errors.Is(err, domain.ErrMediaNotFound) {
+			// deleted while being processed; DeleteMedia only knew about the staged file
+			return u.deleteFiles(media)
+		}
+		return err
+	}
+	if err := u.storage.Delete(staged); err != nil {
+		log.Printf("removing staged media %s failed: %v", staged, err)
+	}
+	return nil
+}
+
+// storedImage is a processed image once its files are in storage
+type storedImage struct {
+	*domain.ProcessedImage
+	size     int64
+	variants []*domain.MediaVariant
+}
+
+// processStaged runs the staged upload through the image processor and stores the
+// cleaned original under the media's key and each variant next to it
+func (u *MediaUsecase) processStaged(media *domain.Media) (_ *storedImage, err error) {
+	body, err := u.storage.Open(media.StagingKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+	result, err := u.images.Process(data, media.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	original := result.Original
+	if original == nil {
+		original = data
+	}
+	image := &storedImage{ProcessedImage: result, size: int64(len(original))}
+	defer func() {
+		// leave nothing behind when only some of the files were stored
+		if err != nil {
+			u.deleteFiles(&domain.Media{Key: media.Key, Variants: image.variants})
+		}
+	}()
+	if err := u.storage.Put(media.Key, bytes.NewReader(original), image.size, media.ContentType); err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(media.Key, path.Ext(media.Key))
+	for _, v := range result.Variants {
+		variant := &domain.MediaVariant{
+			Name:        v.Name,
+			Key:         base + "-" + v.Name + domain.MediaTypes[v.ContentType],
+			ContentType: v.ContentType,
+			Width:       v.Width,
+			Height:      v.Height,
+			Size:        int64(len(v.Data)),
+		}
+		if err := u.storage.Put(variant.Key, bytes.NewReader(v.Data), variant.Size, v.ContentType); err != nil {
+			return nil, err
+		}
+		image.variants = append(image.variants, variant)
+	}
+	return image, nil
+}
+
+// checkBlogMedia makes sure a post only references files from its author's library
+func checkBlogMedia(repo domain.MediaRepository, authorID primitive.ObjectID, mediaIDs []primitive.ObjectID) error {
+	if len(mediaIDs) == 0 {
+		return nil
+	}
+	if len(mediaIDs) > domain.MaxBlogMedia {
+		return fmt.Errorf("a blog may reference at most %d media files", domain.MaxBlogMedia)
+	}
+	media, err := repo.GetMediaByIDs(mediaIDs)
+	if err != nil {
+		return err
+	}
+	owned := map[primitive.ObjectID]bool{}
+	for _, m := range media {
+		owned[m.ID] = m.UserID == authorID
+	}
+	for _, id := range mediaIDs {
+		if !owned[id] {
+			return fmt.Errorf("%w: %s is not in the author's library", domain.ErrMediaNotFound, id.Hex())
+		}
+	}
+	return nil
+}
+
+// mediaFilename keeps the base name of what the client sent, falling back to a generic one
+func mediaFilename(name, ext string) string {
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "upload" + ext
+	}
+	if runes := []rune(name); len(runes) > maxMediaFilename {
+		name = string(runes[:maxMediaFilename])
+	}
+	return name
+}
@@ -0,0 +1,346 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newsletter limits
+const (
+	confirmLinkTTL      = 72 * time.Hour  // how long a confirmation link stays valid
+	confirmResendPause  = 5 * time.Minute // repeated sign-ups within this time send no new email
+	digestMaxPosts      = 20
+	digestBatchSize     = 100
+	digestExcerptLength = 200
+)
+
+// NewsletterUsecase manages email subscriptions with double opt-in and sends periodic digests
+type NewsletterUsecase struct {
+	repo     domain.NewsletterRepository
+	blogRepo domain.BlogRepository
+	userRepo domain.UserRepository
+	mailer   domain.Mailer
+	baseURL  string
+	pages    sitePages
+	period   time.Duration
+}
+
+// constructor for NewsletterUsecase; baseURL is where the confirm and unsubscribe links in
+// emails point, siteURL the frontend post links point to, if any, and period is how often
+// each subscriber gets a digest
+func NewNewsletterUsecase(repo domain.NewsletterRepository, blogRepo domain.BlogRepository, userRepo domain.UserRepository, mailer domain.Mailer, baseURL, siteURL string, period time.Duration) *NewsletterUsecase {
+	return &NewsletterUsecase{
+		repo:     repo,
+		blogRepo: blogRepo,
+		userRepo: userRepo,
+		mailer:   mailer,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		pages:    newSitePages(baseURL, siteURL),
+		period:   period,
+	}
+}
+
+// Subscribe signs an address up for digests of the whole blog, an author or a tag and mails
+// a confirmation link. Known addresses get the same answer so subscriptions cannot be probed.
+func (u *NewsletterUsecase) Subscribe(email, scope, authorID, tag string) error {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return domain.ErrInvalidEmail
+	}
+	email = strings.ToLower(addr.Address)
+
+	var aid primitive.ObjectID
+	switch scope {
+	case "", domain.NewsletterScopeBlog:
+		scope, tag = domain.NewsletterScopeBlog, ""
+	case domain.NewsletterScopeAuthor:
+		if aid, err = primitive.ObjectIDFromHex(authorID); err != nil {
+			return err
+		}
+		if _, err := u.userRepo.GetByID(aid); err != nil {
+			return errors.New("user not found")
+		}
+		tag = ""
+	case domain.NewsletterScopeTag:
+		if tag, err = followTag(tag); err != nil {
+			return err
+		}
+	default:
+		return errors.New("scope must be blog, author or tag")
+	}
+
+	s, err := u.repo.FindSubscription(email, scope, aid, tag)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	switch {
+	case s != nil && s.Status == domain.NewsletterConfirmed:
+		return nil
+	case s != nil && s.Status == domain.NewsletterPending:
+		if now.Sub(s.RequestedAt) < confirmResendPause {
+			return nil
+		}
+		if now.Sub(s.RequestedAt) > confirmLinkTTL {
+			s.ConfirmToken = randomToken()
+		}
+	default:
+		if s == nil {
+			s = &domain.NewsletterSubscription{Email: email, Scope: scope, AuthorID: aid, Tag: tag}
+		}
+		s.Status = domain.NewsletterPending
+		s.ConfirmToken = randomToken()
+		// unsubscribe links in earlier emails keep working after a resubscription
+		if s.UnsubscribeToken == "" {
+			s.UnsubscribeToken = randomToken()
+		}
+		s.ConfirmedAt = nil
+		s.LastSentAt = nil
+	}
+	s.RequestedAt = now
+	if err := u.repo.SaveSubscription(s); err != nil {
+		return err
+	}
+
+	return u.mailer.Send(domain.Email{
+		To:      s.Email,
+		Subject: "Confirm your subscription to new " + u.describe(s),
+		Body: fmt.Sprintf("Someone, hopefully you, asked to receive digests of new %s at this address.\n\n"+
+			"Confirm your subscription:\n%s\n\n"+
+			"If this wasn't you, ignore this email and nothing will be sent. The link expires in %d hours.\n",
+			u.describe(s), u.link("/newsletter/confirm", s.ConfirmToken), int(confirmLinkTTL.Hours())),
+	})
+}
+
+// Confirm activates a pending subscription from its emailed link
+func (u *NewsletterUsecase) Confirm(token string) (*domain.NewsletterSubscription, error) {
+	if token == "" {
+		return nil, domain.ErrInvalidNewsletterToken
+	}
+	s, err := u.repo.GetByConfirmToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil || s.Status == domain.NewsletterUnsubscribed {
+		return nil, domain.ErrInvalidNewsletterToken
+	}
+	if s.Status == domain.NewsletterConfirmed {
+		return s, nil
+	}
+	if time.Since(s.RequestedAt) > confirmLinkTTL {
+		return nil, domain.ErrInvalidNewsletterToken
+	}
+
+	// the first digest covers posts published from now on
+	now := time.Now()
+	s.Status = domain.NewsletterConfirmed
+	s.ConfirmedAt = &now
+	s.LastSentAt = &now
+	if err := u.repo.SaveSubscription(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetUnsubscribe looks up the subscription an unsubscribe link is for, without changing it
+func (u *NewsletterUsecase) GetUnsubscribe(token string) (*domain.NewsletterSubscription, error) {
+	if token == "" {
+		return nil, domain.ErrInvalidNewsletterToken
+	}
+	s, err := u.repo.GetByUnsubscribeToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, domain.ErrInvalidNewsletterToken
+	}
+	return s, nil
+}
+
+// Unsubscribe ends a subscription from the link in any email; repeating it is harmless
+func (u *NewsletterUsecase) Unsubscribe(token string) (*domain.NewsletterSubscription, error) {
+	s, err := u.GetUnsubscribe(token)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != domain.NewsletterUnsubscribed {
+		s.Status = domain.NewsletterUnsubscribed
+		if err := u.repo.SaveSubscription(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// SendDigests mails every subscriber whose period is up the posts published since their
+// last digest. Failed deliveries stay due and are retried on the next run.
+func (u *NewsletterUsecase) SendDigests() (int, error) {
+	start := time.Now()
+	sent := 0
+	for {
+		due, err := u.repo.GetDueSubscriptions(start.Add(-u.period), digestBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		progressed := 0
+		for _, s := range due {
+			ok, err := u.sendDigest(s, start)
+			if err != nil {
+				log.Printf("digest for subscription %s failed: %v", s.ID.Hex(), err)
+				continue
+			}
+			if ok {
+				sent++
+			}
+			progressed++
+		}
+		if len(due) < digestBatchSize || progressed == 0 {
+			return sent, nil
+		}
+	}
+}
+
+// sendDigest mails one subscriber their new posts, if there are any, and moves them to the next
+// period. A digest holds at most digestMaxPosts posts; when more were published, it covers the
+// oldest and the rest go into the next one.
+func (u *NewsletterUsecase) sendDigest(s *domain.NewsletterSubscription, now time.Time) (bool, error) {
+	var author *primitive.ObjectID
+	if s.Scope == domain.NewsletterScopeAuthor {
+		author = &s.AuthorID
+	}
+	since := now.Add(-u.period)
+	if s.LastSentAt != nil {
+		since = *s.LastSentAt
+	}
+	posts, err := u.blogRepo.GetBlogsSince(author, s.Tag, since, digestMaxPosts)
+	if err != nil {
+		return false, err
+	}
+	if len(posts) == 0 {
+		return false, u.repo.MarkSent(s.ID, now)
+	}
+
+	// the digest covers everything up to its newest post when it is full, and otherwise up to
+	// now; posts published while the digest was composed are not covered twice
+	newest := posts[len(posts)-1].DateCreated
+	more := len(posts) == digestMaxPosts
+	sentAt := now
+	if more || newest.After(sentAt) {
+		sentAt = newest
+	}
+	// newest first in the email
+	for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+		posts[i], posts[j] = posts[j], posts[i]
+	}
+
+	unsubscribe := u.link("/newsletter/unsubscribe", s.UnsubscribeToken)
+	var body strings.Builder
+	fmt.Fprintf(&body, "New %s:\n\n", u.describe(s))
+	for _, p := range posts {
+		fmt.Fprintf(&body, "%s\n", p.Title)
+		if p.AuthorName != "" {
+			fmt.Fprintf(&body, "by %s, ", p.AuthorName)
+		}
+		fmt.Fprintf(&body, "%s\n%s\n", p.DateCreated.Format("Jan 2, 2006"), u.pages.post(p.ID))
+		if text := excerpt(p.Content, digestExcerptLength); text != "" {
+			fmt.Fprintf(&body, "%s\n", text)
+		}
+		body.WriteString("\n")
+	}
+	if more {
+		body.WriteString("More new posts follow in your next digest.\n\n")
+	}
+	fmt.Fprintf(&body, "--\nYou are receiving this because you subscribed to new %s.\nUnsubscribe: %s\n", u.describe(s), unsubscribe)
+
+	subject := fmt.Sprintf("Your digest: %d new %s", len(posts), u.describe(s))
+	if len(posts) == 1 {
+		subject = "New post: " + posts[0].Title
+	}
+	err = u.mailer.Send(domain.Email{
+		To:      s.Email,
+		Subject: subject,
+		Body:    body.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, u.repo.MarkSent(s.ID, sentAt)
+}
+
+// Run sends due digests every interval until stop is closed
+func (u *NewsletterUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := u.SendDigests(); err != nil {
+			log.Println("newsletter digests failed:", err)
+		} else if n > 0 {
+			log.Printf("sent %d newsletter digests", n)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// describe names what a subscription covers, e.g. "posts tagged go"
+func (u *NewsletterUsecase) describe(s *domain.NewsletterSubscription) string {
+	switch s.Scope {
+	case domain.NewsletterScopeAuthor:
+		if user, err := u.userRepo.GetByID(s.AuthorID); err == nil {
+			return "posts by " + user.Username
+		}
+		return "posts by an author"
+	case domain.NewsletterScopeTag:
+		return "posts tagged " + s.Tag
+	default:
+		return "posts"
+	}
+}
+
+func (u *NewsletterUsecase) link(path, token string) string {
+	return u.baseURL + path + "?token=" + token
+}
+
+// randomToken returns an unguessable hex token for emailed links
+func randomToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// excerpt shortens text to about max characters on a word boundary, collapsing whitespace
+func excerpt(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	cut := max
+	for cut > max/2 && runes[cut] != ' ' {
+		cut--
+	}
+	return strings.TrimRight(string(runes[:cut]), " ,.;:") + "…"
+}
@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationUsecase turns domain events into notifications stored per user and serves
+// the notification center. It also delivers the moderation notices of ReportUsecase.
+type NotificationUsecase struct {
+	repo        domain.NotificationRepository
+	blogRepo    domain.BlogRepository
+	commentRepo domain.CommentRepository
+	userRepo    domain.UserRepository
+	events      domain.EventBus
+}
+
+// constructor for NotificationUsecase; events are turned into notifications in the background
+func NewNotificationUsecase(repo domain.NotificationRepository, blogRepo domain.BlogRepository, commentRepo domain.CommentRepository, userRepo domain.UserRepository, events domain.EventBus) *NotificationUsecase {
+	u := &NotificationUsecase{repo: repo, blogRepo: blogRepo, commentRepo: commentRepo, userRepo: userRepo, events: events}
+	if events != nil {
+		// building a notification needs a few lookups, so keep it off the request path
+		async := func(handle func(domain.Event)) func(domain.Event) {
+			return func(e domain.Event) { go handle(e) }
+		}
+		events.Subscribe(domain.EventBlogReacted, async(u.onReaction))
+		events.Subscribe(domain.EventCommentCreated, async(u.onComment))
+		events.Subscribe(domain.EventCommentApproved, async(u.onCommentApproved))
+		events.Subscribe(domain.EventAuthorFollowed, async(u.onFollow))
+		events.Subscribe(domain.EventUserRoleChanged, async(u.onRoleChange))
+	}
+	return u
+}
+
+// Notify stores a notification unless the recipient switched its type off
+func (u *NotificationUsecase) Notify(n domain.Notification) error {
+	if optionalNotification(n.Type) {
+		prefs, err := u.repo.GetPreferences(n.UserID)
+		if err != nil {
+			return err
+		}
+		if enabled, ok := prefs[n.Type]; ok && !enabled {
+			return nil
+		}
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	n.Read = false
+	if err := u.repo.CreateNotification(&n); err != nil {
+		return err
+	}
+	publish(u.events, domain.Event{Type: domain.EventNotificationCreated, Payload: &n})
+	return nil
+}
+
+// a new reaction notifies the author; switching reactions and dislikes do not
+func (u *NotificationUsecase) onReaction(e domain.Event) {
+	change, ok := e.Payload.(domain.ReactionChange)
+	if !ok || change.Previous != "" || change.Current == "" || change.Current == domain.ReactionDislike {
+		return
+	}
+	blog, err := u.blogRepo.GetBlogByID(e.BlogID)
+	if err != nil || blog.UserID == e.ActorID {
+		return
+	}
+
+	message := fmt.Sprintf("%s reacted with %s to your post %q", u.username(e.ActorID), change.Current, blog.Title)
+	if change.Current == domain.ReactionLike {
+		message = fmt.Sprintf("%s liked your post %q", u.username(e.ActorID), blog.Title)
+	}
+	u.deliver(domain.Notification{UserID: blog.UserID, Type: domain.NotificationReaction, Message: message}, e.ActorID, &blog.ID, nil)
+}
+
+// a comment notifies the post's author, and a published reply the author of the parent comment
+func (u *NotificationUsecase) onComment(e domain.Event) {
+	comment, ok := e.Payload.(*domain.Comment)
+	if !ok {
+		return
+	}
+	blog, err := u.blogRepo.GetBlogByID(comment.BlogID)
+	if err != nil {
+		return
+	}
+
+	repliedTo := primitive.NilObjectID
+	if comment.Status == domain.CommentStatusApproved {
+		repliedTo = u.notifyReply(comment, blog)
+	}
+	// the author already heard about it if the reply was to their own comment
+	if blog.UserID == comment.UserID || blog.UserID == repliedTo {
+		return
+	}
+
+	message := fmt.Sprintf("%s commented on your post %q", u.username(comment.UserID), blog.Title)
+	if comment.Status == domain.CommentStatusPending {
+		message += " and is waiting for your approval"
+	}
+	u.deliver(domain.Notification{UserID: blog.UserID, Type: domain.NotificationComment, Message: message}, comment.UserID, &blog.ID, &comment.ID)
+}
+
+// replies held for approval reach the parent comment's author once approved
+func (u *NotificationUsecase) onCommentApproved(e domain.Event) {
+	comment, ok := e.Payload.(*domain.Comment)
+	if !ok {
+		return
+	}
+	blog, err := u.blogRepo.GetBlogByID(comment.BlogID)
+	if err != nil {
+		return
+	}
+	u.notifyReply(comment, blog)
+}
+
+// notifyReply tells the parent comment's author about a reply and returns who was notified
+func (u *NotificationUsecase) notifyReply(comment *domain.Comment, blog *domain.Blog) primitive.ObjectID {
+	if comment.ParentID == nil {
+		return primitive.NilObjectID
+	}
+	parent, err := u.commentRepo.GetCommentByID(*comment.ParentID)
+	if err != nil || parent.UserID == comment.UserID {
+		return primitive.NilObjectID
+	}
+
+	message := fmt.Sprintf("%s replied to your comment on %q", u.username(comment.UserID), blog.Title)
+	u.deliver(domain.Notification{UserID: parent.UserID, Type: domain.NotificationReply, Message: message}, comment.UserID, &blog.ID, &comment.ID)
+	return parent.UserID
+}
+
+func (u *NotificationUsecase) onFollow(e domain.Event) {
+	authorID, ok := e.Payload.(primitive.ObjectID)
+	if !ok {
+		return
+	}
+	message := fmt.Sprintf("%s started following you", u.username(e.ActorID))
+	u.deliver(domain.Notification{UserID: authorID, Type: domain.NotificationNewFollower, Message: message}, e.ActorID, nil, nil)
+}
+
+func (u *NotificationUsecase) onRoleChange(e domain.Event) {
+	change, ok := e.Payload.(domain.RoleChange)
+	if !ok {
+		return
+	}
+	message := fmt.Sprintf("Your role was changed to %s", change.Role)
+	u.deliver(domain.Notification{UserID: change.UserID, Type: domain.NotificationRoleChanged, Message: message}, e.ActorID, nil, nil)
+}
+
+// helper to fill in the references of a notification and store it; failures are only logged
+func (u *NotificationUsecase) deliver(n domain.Notification, actorID primitive.ObjectID, blogID, commentID *primitive.ObjectID) {
+	if !actorID.IsZero() {
+		n.ActorID = &actorID
+	}
+	n.BlogID = blogID
+	n.CommentID = commentID
+	if err := u.Notify(n); err != nil {
+		log.Printf("failed to notify user %s: %v", n.UserID.Hex(), err)
+	}
+}
+
+// helper to name the user behind a notification
+func (u *NotificationUsecase) username(id primitive.ObjectID) string {
+	if user, err := u.userRepo.GetByID(id); err == nil && user.Username != "" {
+		return user.Username
+	}
+	return "Someone"
+}
+
+// GetNotifications lists the user's notifications newest first, with the unread total
+func (u *NotificationUsecase) GetNotifications(userID string, unreadOnly bool, page domain.PageRequest) (*domain.NotificationList, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := u.repo.GetNotifications(uid, unreadOnly, page.Normalize())
+	if err != nil {
+		return nil, err
+	}
+	unread, err := u.repo.CountUnread(uid)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NotificationList{Page: *notifications, Unread: unread}, nil
+}
+
+func (u *NotificationUsecase) MarkRead(userID, notificationID string) error {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	nid, err := primitive.ObjectIDFromHex(notificationID)
+	if err != nil {
+		return domain.ErrNotificationNotFound
+	}
+	return u.repo.MarkRead(uid, nid)
+}
+
+func (u *NotificationUsecase) MarkAllRead(userID string) (int, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, err
+	}
+	return u.repo.MarkAllRead(uid)
+}
+
+// GetPreferences reports every optional notification type and whether it is on (the default)
+func (u *NotificationUsecase) GetPreferences(userID string) (map[string]bool, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := u.repo.GetPreferences(uid)
+	if err != nil {
+		return nil, err
+	}
+	prefs := make(map[string]bool, len(domain.NotificationPreferenceTypes))
+	for _, kind := range domain.NotificationPreferenceTypes {
+		enabled, ok := stored[kind]
+		prefs[kind] = !ok || enabled
+	}
+	return prefs, nil
+}
+
+// SetPreferences switches notification types on or off; types left out keep their setting
+func (u *NotificationUsecase) SetPreferences(userID string, prefs map[string]bool) (map[string]bool, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	for kind := range prefs {
+		if !optionalNotification(kind) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownNotificationType, kind)
+		}
+	}
+	if err := u.repo.SetPreferences(uid, prefs); err != nil {
+		return nil, err
+	}
+	return u.GetPreferences(userID)
+}
+
+// optionalNotification reports whether users may switch a notification type off
+func optionalNotification(kind string) bool {
+	for _, t := range domain.NotificationPreferenceTypes {
+		if t == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reaction names become field names in the counters document
+var reactionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// ParseReactionTypes reads a reaction set such as "like:👍,love:❤️,celebrate:🎉".
+// like and dislike are always included since the like/dislike endpoints depend on them.
+func ParseReactionTypes(spec string) ([]domain.ReactionType, error) {
+	if strings.TrimSpace(spec) == "" {
+		return domain.DefaultReactionTypes, nil
+	}
+
+	var types []domain.ReactionType
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(spec, ",") {
+		name, emoji, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !reactionNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid reaction name %q", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		types = append(types, domain.ReactionType{Name: name, Emoji: strings.TrimSpace(emoji)})
+	}
+	for _, required := range domain.DefaultReactionTypes[:2] {
+		if !seen[required.Name] {
+			types = append([]domain.ReactionType{required}, types...)
+		}
+	}
+	return types, nil
+}
+
+type ReactionUsecase struct {
+	repo   domain.BlogRepository
+	types  []domain.ReactionType
+	known  map[string]bool
+	events domain.EventBus
+}
+
+func NewReactionUsecase(repo domain.BlogRepository, types []domain.ReactionType, events domain.EventBus) *ReactionUsecase {
+	known := map[string]bool{}
+	for _, t := range types {
+		known[t.Name] = true
+	}
+	return &ReactionUsecase{repo: repo, types: types, known: known, events: events}
+}
+
+// Types lists the configured reactions
+func (u *ReactionUsecase) Types() []domain.ReactionType {
+	return u.types
+}
+
+// SetReaction leaves a reaction on a blog, replacing the user's previous one, and returns the new counters
+func (u *ReactionUsecase) SetReaction(userID, blogID, reaction string) (map[string]int, error) {
+	if !u.known[reaction] {
+		return nil, domain.ErrUnknownReaction
+	}
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := react(u.repo, u.events, uid, bid, reaction); err != nil {
+		return nil, err
+	}
+	return u.counters(bid)
+}
+
+// RemoveReaction withdraws the user's reaction; it must be the one currently set
+func (u *ReactionUsecase) RemoveReaction(userID, blogID, reaction string) (map[string]int, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unreact(u.repo, u.events, uid, bid, reaction); err != nil {
+		return nil, err
+	}
+	return u.counters(bid)
+}
+
+// Reconcile recomputes reaction counters from the interaction log
+func (u *ReactionUsecase) Reconcile() (int, error) {
+	return u.repo.ReconcileReactions()
+}
+
+// Run reconciles counters every interval until stop is closed
+func (u *ReactionUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if n, err := u.Reconcile(); err != nil {
+				log.Println("reaction reconciliation failed:", err)
+			} else if n > 0 {
+				log.Printf("reaction counters corrected on %d blogs", n)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (u *ReactionUsecase) counters(blogID primitive.ObjectID) (map[string]int, error) {
+	blog, err := u.repo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, errors.New("blog not found")
+	}
+	if blog.Reactions == nil {
+		return map[string]int{}, nil
+	}
+	return blog.Reactions, nil
+}
+
+// react stores a reaction and publishes the matching events. It is shared with the
+// like/dislike endpoints of BlogUsecase.
+func react(repo domain.BlogRepository, events domain.EventBus, userID, blogID primitive.ObjectID, reaction string) (string, error) {
+	previous, err := repo.SetReaction(userID, blogID, reaction)
+	if err != nil {
+		return "", err
+	}
+	if previous == reaction {
+		return previous, nil
+	}
+
+	change := domain.ReactionChange{Previous: previous, Current: reaction}
+	if blog, err := repo.GetBlogByID(blogID); err == nil {
+		change.Reactions = blog.Reactions
+	}
+	publish(events, domain.Event{Type: domain.EventBlogReacted, ActorID: userID, BlogID: blogID, Payload: change})
+
+	switch reaction {
+	case domain.ReactionLike:
+		publish(events, domain.Event{Type: domain.EventBlogLiked, ActorID: userID, BlogID: blogID})
+	case domain.ReactionDislike:
+		publish(events, domain.Event{Type: domain.EventBlogDisliked, ActorID: userID, BlogID: blogID})
+	}
+	return previous, nil
+}
+
+// unreact removes a reaction and publishes the change; shared like react
+func unreact(repo domain.BlogRepository, events domain.EventBus, userID, blogID primitive.ObjectID, reaction string) error {
+	if err := repo.RemoveReaction(userID, blogID, reaction); err != nil {
+		return err
+	}
+
+	change := domain.ReactionChange{Previous: reaction}
+	if blog, err := repo.GetBlogByID(blogID); err == nil {
+		change.Reactions = blog.Reactions
+	}
+	publish(events, domain.Event{Type: domain.EventBlogReacted, ActorID: userID, BlogID: blogID, Payload: change})
+	return nil
+}
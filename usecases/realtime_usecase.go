@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RealtimeUsecase forwards domain events to live connections through a pub/sub hub:
+// reaction counters to everyone watching a blog and notifications to their recipient
+type RealtimeUsecase struct {
+	hub      domain.PubSub
+	blogRepo domain.BlogRepository
+	upgrader domain.WebSocketUpgrader
+}
+
+// constructor for RealtimeUsecase
+func NewRealtimeUsecase(hub domain.PubSub, blogRepo domain.BlogRepository, upgrader domain.WebSocketUpgrader, events domain.EventBus) *RealtimeUsecase {
+	u := &RealtimeUsecase{hub: hub, blogRepo: blogRepo, upgrader: upgrader}
+	if events != nil {
+		events.Subscribe(domain.EventBlogReacted, func(e domain.Event) {
+			change, ok := e.Payload.(domain.ReactionChange)
+			if !ok || change.Reactions == nil {
+				return
+			}
+			hub.Publish(domain.BlogTopic(e.BlogID), domain.RealtimeMessage{
+				Type: domain.RealtimeReactions,
+				Data: domain.ReactionCounts{BlogID: e.BlogID, Reactions: change.Reactions},
+			})
+		})
+		events.Subscribe(domain.EventNotificationCreated, func(e domain.Event) {
+			n, ok := e.Payload.(*domain.Notification)
+			if !ok {
+				return
+			}
+			hub.Publish(domain.UserTopic(n.UserID), domain.RealtimeMessage{Type: domain.RealtimeNotification, Data: n})
+		})
+	}
+	return u
+}
+
+// Subscribe opens a live subscription to the user's notifications (when signed in) and the
+// reaction counters of the given blogs. The current counters are returned to start from.
+func (u *RealtimeUsecase) Subscribe(userID string, blogIDs []string) (domain.Subscription, []domain.RealtimeMessage, error) {
+	var topics []string
+	if uid, err := primitive.ObjectIDFromHex(userID); err == nil {
+		topics = append(topics, domain.UserTopic(uid))
+	}
+	// refuse before looking any blog up
+	if len(topics)+len(blogIDs) > domain.MaxSubscriptionTopics {
+		return nil, nil, domain.ErrTooManyTopics
+	}
+
+	var initial []domain.RealtimeMessage
+	for _, raw := range blogIDs {
+		msg, err := u.blogSnapshot(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		topics = append(topics, msg.Topic)
+		initial = append(initial, msg)
+	}
+
+	sub, err := u.hub.Subscribe(topics...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sub, initial, nil
+}
+
+// Watch adds a blog to an open subscription and returns its current counters
+func (u *RealtimeUsecase) Watch(sub domain.Subscription, blogID string) (domain.RealtimeMessage, error) {
+	msg, err := u.blogSnapshot(blogID)
+	if err != nil {
+		return msg, err
+	}
+	return msg, sub.Add(msg.Topic)
+}
+
+// Unwatch stops the reaction updates of a blog
+func (u *RealtimeUsecase) Unwatch(sub domain.Subscription, blogID string) error {
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return err
+	}
+	sub.Remove(domain.BlogTopic(bid))
+	return nil
+}
+
+// OpenWebSocket turns the request into a WebSocket for pushing a subscription's messages
+func (u *RealtimeUsecase) OpenWebSocket(w http.ResponseWriter, r *http.Request) (domain.WebSocket, error) {
+	return u.upgrader.Upgrade(w, r)
+}
+
+// blogSnapshot checks that a blog can be watched and reads its counters
+func (u *RealtimeUsecase) blogSnapshot(blogID string) (domain.RealtimeMessage, error) {
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return domain.RealtimeMessage{}, err
+	}
+	blog, err := u.blogRepo.GetBlogByID(bid)
+	if err != nil || blog.Status == domain.BlogStatusHidden {
+		return domain.RealtimeMessage{}, errors.New("blog not found")
+	}
+
+	reactions := blog.Reactions
+	if reactions == nil {
+		reactions = map[string]int{}
+	}
+	return domain.RealtimeMessage{
+		Topic: domain.BlogTopic(bid),
+		Type:  domain.RealtimeReactions,
+		Data:  domain.ReactionCounts{BlogID: bid, Reactions: reactions},
+	}, nil
+}
@@ -0,0 +1,221 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// how much each signal contributes to the final related-post score
+const (
+	relatedTagWeight    = 0.4
+	relatedCoLikeWeight = 0.35
+	relatedTextWeight   = 0.25
+)
+
+// candidates fetched per signal, results kept per blog and how long they stay cached
+const (
+	relatedCandidates = 50
+	relatedMaxResults = 20
+	relatedCacheTTL   = 30 * time.Minute
+)
+
+// RelatedUsecase recommends posts similar to the one a reader just finished
+type RelatedUsecase struct {
+	blogRepo domain.BlogRepository
+	search   domain.SearchEngine
+	cache    *ttlCache[primitive.ObjectID, []*domain.RelatedBlog]
+}
+
+// constructor for RelatedUsecase; cached results are dropped when blogs change
+func NewRelatedUsecase(blogRepo domain.BlogRepository, search domain.SearchEngine, events domain.EventBus) *RelatedUsecase {
+	u := &RelatedUsecase{
+		blogRepo: blogRepo,
+		search:   search,
+		cache:    newTTLCache[primitive.ObjectID, []*domain.RelatedBlog](relatedCacheTTL),
+	}
+	if events != nil {
+		// an edit only changes the post's own recommendations
+		events.Subscribe(domain.EventBlogUpdated, func(e domain.Event) { u.cache.Delete(e.BlogID) })
+		// a post disappearing may be listed under any other post
+		events.Subscribe(domain.EventBlogDeleted, func(e domain.Event) { u.cache.Clear() })
+		events.Subscribe(domain.EventBlogStatusChanged, func(e domain.Event) { u.cache.Clear() })
+	}
+	return u
+}
+
+// GetRelated ranks other posts by tag overlap, co-likes and textual similarity
+func (u *RelatedUsecase) GetRelated(blogID string, limit int) ([]*domain.RelatedBlog, error) {
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > relatedMaxResults {
+		limit = 5
+	}
+
+	related, ok := u.cache.Get(bid)
+	if !ok {
+		related, err = u.compute(bid)
+		if err != nil {
+			return nil, err
+		}
+		u.cache.Set(bid, related)
+	}
+
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related, nil
+}
+
+func (u *RelatedUsecase) compute(bid primitive.ObjectID) ([]*domain.RelatedBlog, error) {
+	source, err := u.blogRepo.GetBlogByID(bid)
+	if err != nil {
+		return nil, err
+	}
+	if source.Status == domain.BlogStatusHidden {
+		return nil, domain.ErrBlogNotFound
+	}
+
+	type candidate struct {
+		blog               *domain.Blog
+		tags, coLike, text float64
+	}
+	candidates := map[primitive.ObjectID]*candidate{}
+	get := func(id primitive.ObjectID) *candidate {
+		c, ok := candidates[id]
+		if !ok {
+			c = &candidate{}
+			candidates[id] = c
+		}
+		return c
+	}
+
+	// shared tags, scored by Jaccard similarity
+	byTags, err := u.blogRepo.FindBlogsByTags(source.Tags, bid, relatedCandidates)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range byTags {
+		c := get(b.ID)
+		c.blog = b
+		c.tags = jaccard(source.Tags, b.Tags)
+	}
+
+	// readers who liked this post also liked...
+	coLiked, err := u.blogRepo.GetCoLikedBlogs(bid, relatedCandidates)
+	if err != nil {
+		return nil, err
+	}
+	maxCoLike := 0
+	for _, n := range coLiked {
+		if n > maxCoLike {
+			maxCoLike = n
+		}
+	}
+	for id, n := range coLiked {
+		get(id).coLike = float64(n) / float64(maxCoLike)
+	}
+
+	// textual similarity through the search engine, querying with the title and tags
+	if u.search != nil {
+		terms := searchTerms(source.Title + " " + strings.Join(source.Tags, " "))
+		if len(terms) > 0 {
+			hits, err := u.search.Search(terms, domain.PageRequest{Limit: relatedCandidates})
+			if err == nil {
+				maxScore := 0.0
+				for _, h := range hits.Items {
+					if h.Blog.ID != bid && h.Score > maxScore {
+						maxScore = h.Score
+					}
+				}
+				for _, h := range hits.Items {
+					if h.Blog.ID == bid || maxScore == 0 {
+						continue
+					}
+					c := get(h.Blog.ID)
+					c.text = h.Score / maxScore
+					if c.blog == nil {
+						c.blog = h.Blog
+					}
+				}
+			}
+		}
+	}
+	delete(candidates, bid)
+
+	// co-liked posts and search hits may still need loading, and must still be visible
+	var missing []primitive.ObjectID
+	for id, c := range candidates {
+		if c.blog == nil {
+			missing = append(missing, id)
+		}
+	}
+	loaded, err := u.blogRepo.GetBlogsByIDs(missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range loaded {
+		candidates[b.ID].blog = b
+	}
+
+	related := make([]*domain.RelatedBlog, 0, len(candidates))
+	for _, c := range candidates {
+		if c.blog == nil || c.blog.Status == domain.BlogStatusHidden {
+			continue
+		}
+		r := &domain.RelatedBlog{
+			Blog:    c.blog,
+			Score:   relatedTagWeight*c.tags + relatedCoLikeWeight*c.coLike + relatedTextWeight*c.text,
+			Reasons: []string{},
+		}
+		if c.tags > 0 {
+			r.Reasons = append(r.Reasons, "shared_tags")
+		}
+		if c.coLike > 0 {
+			r.Reasons = append(r.Reasons, "liked_by_same_readers")
+		}
+		if c.text > 0 {
+			r.Reasons = append(r.Reasons, "similar_text")
+		}
+		if r.Score > 0 {
+			related = append(related, r)
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].Blog.ID.Hex() > related[j].Blog.ID.Hex()
+	})
+	if len(related) > relatedMaxResults {
+		related = related[:relatedMaxResults]
+	}
+	return related, nil
+}
+
+// jaccard is |a ∩ b| / |a ∪ b| over case-insensitive tags
+func jaccard(a, b []string) float64 {
+	set := map[string]int{}
+	for _, t := range a {
+		set[strings.ToLower(t)] |= 1
+	}
+	for _, t := range b {
+		set[strings.ToLower(t)] |= 2
+	}
+	inter := 0
+	for _, v := range set {
+		if v == 3 {
+			inter++
+		}
+	}
+	if len(set) == 0 {
+		return 0
+	}
+	return float64(inter) / float64(len(set))
+}
@@ -0,0 +1,217 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReportUsecase struct {
+	repo          domain.ReportRepository
+	blogRepo      domain.BlogRepository
+	notifier      domain.Notifier
+	events        domain.EventBus
+	hideThreshold int64
+}
+
+// constructor for ReportUsecase; posts are hidden once they collect hideThreshold open reports
+func NewReportUsecase(repo domain.ReportRepository, blogRepo domain.BlogRepository, notifier domain.Notifier, events domain.EventBus, hideThreshold int) *ReportUsecase {
+	if hideThreshold < 1 {
+		hideThreshold = 1
+	}
+	return &ReportUsecase{repo: repo, blogRepo: blogRepo, notifier: notifier, events: events, hideThreshold: int64(hideThreshold)}
+}
+
+// ReportBlog files a report and hides the post once the threshold is reached
+func (u *ReportUsecase) ReportBlog(reporterID, blogID, reason, details string) (*domain.Report, error) {
+	uid, err := primitive.ObjectIDFromHex(reporterID)
+	if err != nil {
+		return nil, err
+	}
+	bid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason = strings.ToLower(strings.TrimSpace(reason))
+	if !validReportReason(reason) {
+		return nil, fmt.Errorf("reason must be one of %s", strings.Join(domain.ReportReasons, ", "))
+	}
+
+	blog, err := u.blogRepo.GetBlogByID(bid)
+	if err != nil || blog.Status == domain.BlogStatusHidden {
+		return nil, errors.New("blog not found")
+	}
+
+	already, err := u.repo.HasOpenReport(uid, bid)
+	if err != nil {
+		return nil, err
+	}
+	if already {
+		return nil, domain.ErrAlreadyReported
+	}
+
+	report := domain.Report{
+		ID:         primitive.NewObjectID(),
+		BlogID:     bid,
+		ReporterID: uid,
+		Reason:     reason,
+		Details:    strings.TrimSpace(details),
+		Status:     domain.ReportStatusOpen,
+		CreatedAt:  time.Now(),
+	}
+	created, err := u.repo.CreateReport(&report)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := u.repo.CountReports(bid, domain.ReportStatusOpen)
+	if err != nil {
+		return nil, err
+	}
+	if open >= u.hideThreshold {
+		if err := u.blogRepo.SetBlogStatus(bid, domain.BlogStatusHidden); err != nil {
+			return nil, err
+		}
+		u.statusChanged(bid, primitive.NilObjectID)
+		u.notify(blog, domain.NotificationBlogHidden,
+			fmt.Sprintf("Your post %q has been hidden pending review after being reported by readers.", blog.Title))
+	}
+
+	return created, nil
+}
+
+// GetReports returns the admin triage queue, open reports by default
+func (u *ReportUsecase) GetReports(status string) ([]*domain.Report, error) {
+	switch status {
+	case "":
+		status = domain.ReportStatusOpen
+	case "all":
+		status = ""
+	case domain.ReportStatusOpen, domain.ReportStatusResolved, domain.ReportStatusDismissed:
+	default:
+		return nil, errors.New("status must be open, resolved, dismissed or all")
+	}
+	return u.repo.GetReports(status)
+}
+
+// ResolveReport upholds a report and takes the post down
+func (u *ReportUsecase) ResolveReport(adminID, reportID, note string) error {
+	report, aid, err := u.openReport(adminID, reportID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repo.UpdateReportStatus(report.ID, domain.ReportStatusResolved, aid, note); err != nil {
+		return err
+	}
+	if err := u.blogRepo.SetBlogStatus(report.BlogID, domain.BlogStatusHidden); err != nil {
+		return err
+	}
+	u.statusChanged(report.BlogID, aid)
+
+	if blog, err := u.blogRepo.GetBlogByID(report.BlogID); err == nil {
+		u.notify(blog, domain.NotificationReportResolved,
+			fmt.Sprintf("Your post %q was removed after review for: %s.", blog.Title, report.Reason))
+	}
+	return nil
+}
+
+// DismissReport rejects a report, republishing the post if nothing else keeps it hidden
+func (u *ReportUsecase) DismissReport(adminID, reportID, note string) error {
+	report, aid, err := u.openReport(adminID, reportID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repo.UpdateReportStatus(report.ID, domain.ReportStatusDismissed, aid, note); err != nil {
+		return err
+	}
+
+	blog, err := u.blogRepo.GetBlogByID(report.BlogID)
+	if err != nil || blog.Status != domain.BlogStatusHidden {
+		return nil
+	}
+
+	open, err := u.repo.CountReports(report.BlogID, domain.ReportStatusOpen)
+	if err != nil {
+		return err
+	}
+	upheld, err := u.repo.CountReports(report.BlogID, domain.ReportStatusResolved)
+	if err != nil {
+		return err
+	}
+	if open >= u.hideThreshold || upheld > 0 {
+		return nil
+	}
+
+	if err := u.blogRepo.SetBlogStatus(report.BlogID, domain.BlogStatusPublished); err != nil {
+		return err
+	}
+	u.statusChanged(report.BlogID, aid)
+	u.notify(blog, domain.NotificationBlogRestored,
+		fmt.Sprintf("Your post %q has been reviewed and is visible again.", blog.Title))
+	return nil
+}
+
+// helper to load a report that is still awaiting triage
+func (u *ReportUsecase) openReport(adminID, reportID string) (*domain.Report, primitive.ObjectID, error) {
+	aid, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+	rid, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	report, err := u.repo.GetReportByID(rid)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+	if report.Status != domain.ReportStatusOpen {
+		return nil, primitive.NilObjectID, errors.New("report has already been triaged")
+	}
+	return report, aid, nil
+}
+
+// helper to announce a visibility change with the stored blog
+func (u *ReportUsecase) statusChanged(blogID, actorID primitive.ObjectID) {
+	blog, err := u.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return
+	}
+	publish(u.events, domain.Event{Type: domain.EventBlogStatusChanged, ActorID: actorID, BlogID: blogID, Blog: blog})
+}
+
+// helper to tell a blog's author about a moderation outcome; delivery failures are only logged
+func (u *ReportUsecase) notify(blog *domain.Blog, kind, message string) {
+	if u.notifier == nil {
+		return
+	}
+	blogID := blog.ID
+	err := u.notifier.Notify(domain.Notification{
+		UserID:    blog.UserID,
+		Type:      kind,
+		Message:   message,
+		BlogID:    &blogID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("failed to notify author %s: %v", blog.UserID.Hex(), err)
+	}
+}
+
+func validReportReason(reason string) bool {
+	for _, r := range domain.ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// limits for semantic search and the text sent to the embedder
+const (
+	semanticDefaultLimit = 10
+	semanticMaxLimit     = 50
+	embedBatchSize       = 16
+	embedMaxChars        = 8000
+)
+
+// SemanticUsecase finds posts by meaning rather than shared keywords. Vectors are
+// persisted per blog and searched in process by cosine similarity.
+type SemanticUsecase struct {
+	blogRepo domain.BlogRepository
+	repo     domain.EmbeddingRepository
+	embedder domain.Embedder
+
+	mu      sync.RWMutex
+	vectors map[primitive.ObjectID][]float32 // unit length, so cosine similarity is a dot product
+	hashes  map[primitive.ObjectID]string
+}
+
+// constructor for SemanticUsecase; blogs are re-embedded in the background as they change
+func NewSemanticUsecase(blogRepo domain.BlogRepository, repo domain.EmbeddingRepository, embedder domain.Embedder, events domain.EventBus) *SemanticUsecase {
+	u := &SemanticUsecase{
+		blogRepo: blogRepo,
+		repo:     repo,
+		embedder: embedder,
+		vectors:  map[primitive.ObjectID][]float32{},
+		hashes:   map[primitive.ObjectID]string{},
+	}
+	if events != nil {
+		// embedding calls out to the AI provider, so keep it off the request path
+		update := func(e domain.Event) {
+			go func() {
+				if err := u.embedBlog(e.Blog); err != nil {
+					log.Printf("embedding blog %s failed: %v", e.BlogID.Hex(), err)
+				}
+			}()
+		}
+		events.Subscribe(domain.EventBlogCreated, update)
+		events.Subscribe(domain.EventBlogUpdated, update)
+		events.Subscribe(domain.EventBlogStatusChanged, update)
+		events.Subscribe(domain.EventBlogDeleted, func(e domain.Event) {
+			if err := u.remove(e.BlogID); err != nil {
+				log.Printf("removing embedding of blog %s failed: %v", e.BlogID.Hex(), err)
+			}
+		})
+	}
+	return u
+}
+
+// Reindex loads stored vectors and embeds every visible blog that is missing or out of date
+func (u *SemanticUsecase) Reindex() (int, error) {
+	stored, err := u.repo.GetEmbeddings(u.embedder.Model())
+	if err != nil {
+		return 0, err
+	}
+	u.mu.Lock()
+	for _, e := range stored {
+		u.vectors[e.BlogID] = unitVector(e.Vector)
+		u.hashes[e.BlogID] = e.ContentHash
+	}
+	u.mu.Unlock()
+
+	count := 0
+	page := domain.PageRequest{Limit: domain.MaxPageSize}
+	for {
+		blogs, err := u.blogRepo.GetAllBlogs(page)
+		if err != nil {
+			return count, err
+		}
+
+		var stale []*domain.Blog
+		u.mu.RLock()
+		for _, blog := range blogs.Items {
+			if u.hashes[blog.ID] != contentHash(embeddingText(blog)) {
+				stale = append(stale, blog)
+			}
+		}
+		u.mu.RUnlock()
+
+		for start := 0; start < len(stale); start += embedBatchSize {
+			end := start + embedBatchSize
+			if end > len(stale) {
+				end = len(stale)
+			}
+			if err := u.embedBlogs(stale[start:end]); err != nil {
+				return count, err
+			}
+			count += end - start
+		}
+
+		if !blogs.HasMore {
+			return count, nil
+		}
+		page.Cursor = blogs.NextCursor
+	}
+}
+
+// Search embeds the query and returns the closest visible blogs, most similar first
+func (u *SemanticUsecase) Search(query string, limit int) ([]*domain.SearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, domain.ErrEmptySearchQuery
+	}
+	if limit <= 0 || limit > semanticMaxLimit {
+		limit = semanticDefaultLimit
+	}
+
+	vectors, err := u.embed([]string{query})
+	if err != nil {
+		return nil, err
+	}
+	q := unitVector(vectors[0])
+
+	type match struct {
+		id    primitive.ObjectID
+		score float64
+	}
+	u.mu.RLock()
+	matches := make([]match, 0, len(u.vectors))
+	for id, v := range u.vectors {
+		if len(v) == len(q) {
+			matches = append(matches, match{id: id, score: dot(q, v)})
+		}
+	}
+	u.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].id.Hex() > matches[j].id.Hex()
+	})
+	// a few spare candidates in case some were hidden since they were embedded
+	if len(matches) > limit*2 {
+		matches = matches[:limit*2]
+	}
+
+	ids := make([]primitive.ObjectID, len(matches))
+	for i, m := range matches {
+		ids[i] = m.id
+	}
+	blogs, err := u.blogRepo.GetBlogsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*domain.Blog, len(blogs))
+	for _, b := range blogs {
+		byID[b.ID] = b
+	}
+
+	terms := searchTerms(query)
+	hits := []*domain.SearchHit{}
+	for _, m := range matches {
+		blog, ok := byID[m.id]
+		if !ok {
+			continue
+		}
+		hits = append(hits, &domain.SearchHit{
+			Blog:       blog,
+			Score:      math.Round(m.score*1e6) / 1e6,
+			Highlights: buildHighlights(blog, terms),
+		})
+		if len(hits) == limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+// embedBlog refreshes the vector of one blog; hidden blogs are dropped from the index
+func (u *SemanticUsecase) embedBlog(blog *domain.Blog) error {
+	if blog == nil {
+		return nil
+	}
+	if blog.Status == domain.BlogStatusHidden {
+		return u.remove(blog.ID)
+	}
+
+	u.mu.RLock()
+	current := u.hashes[blog.ID] == contentHash(embeddingText(blog))
+	u.mu.RUnlock()
+	if current {
+		return nil
+	}
+	return u.embedBlogs([]*domain.Blog{blog})
+}
+
+// embed runs the embedder and checks that it returned one non-empty vector per text
+func (u *SemanticUsecase) embed(texts []string) ([][]float32, error) {
+	vectors, err := u.embedder.Embed(texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embedder returned an empty vector for text %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// embedBlogs embeds a batch of blogs in one call and stores the vectors
+func (u *SemanticUsecase) embedBlogs(blogs []*domain.Blog) error {
+	texts := make([]string, len(blogs))
+	for i, blog := range blogs {
+		texts[i] = embeddingText(blog)
+	}
+	vectors, err := u.embed(texts)
+	if err != nil {
+		return err
+	}
+
+	for i, blog := range blogs {
+		e := &domain.BlogEmbedding{
+			BlogID:      blog.ID,
+			Model:       u.embedder.Model(),
+			Vector:      vectors[i],
+			ContentHash: contentHash(texts[i]),
+			UpdatedAt:   time.Now(),
+		}
+		if err := u.repo.SaveEmbedding(e); err != nil {
+			return err
+		}
+
+		u.mu.Lock()
+		u.vectors[blog.ID] = unitVector(e.Vector)
+		u.hashes[blog.ID] = e.ContentHash
+		u.mu.Unlock()
+	}
+	return nil
+}
+
+func (u *SemanticUsecase) remove(blogID primitive.ObjectID) error {
+	u.mu.Lock()
+	delete(u.vectors, blogID)
+	delete(u.hashes, blogID)
+	u.mu.Unlock()
+	return u.repo.DeleteEmbedding(blogID)
+}
+
+// embeddingText is what gets embedded for a blog: title, tags and the start of the content
+func embeddingText(blog *domain.Blog) string {
+	text := blog.Title + "\n" + strings.Join(blog.Tags, ", ") + "\n" + blog.Content
+	if len(text) > embedMaxChars {
+		// cut on a rune boundary
+		cut := embedMaxChars
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut]
+	}
+	return text
+}
+
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:16])
+}
+
+// unitVector returns a copy of v scaled to length 1
+func unitVector(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if sum == 0 {
+		return out
+	}
+	norm := math.Sqrt(sum)
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
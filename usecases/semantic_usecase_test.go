@@ -0,0 +1,188 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeEmbedder places texts on one axis per known topic word, so similarity is predictable
+type fakeEmbedder struct {
+	topics []string
+	calls  int
+	texts  int
+	drop   int // vectors left off the end, breaking the one vector per text contract
+}
+
+func (e *fakeEmbedder) Model() string { return "fake" }
+
+func (e *fakeEmbedder) Embed(texts []string) ([][]float32, error) {
+	e.calls++
+	e.texts += len(texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, len(e.topics))
+		for j, topic := range e.topics {
+			v[j] = float32(strings.Count(strings.ToLower(text), topic))
+		}
+		vectors[i] = v
+	}
+	return vectors[:max(len(vectors)-e.drop, 0)], nil
+}
+
+type fakeEmbeddingRepo struct {
+	saved map[primitive.ObjectID]*domain.BlogEmbedding
+}
+
+func (r *fakeEmbeddingRepo) SaveEmbedding(e *domain.BlogEmbedding) error {
+	r.saved[e.BlogID] = e
+	return nil
+}
+
+func (r *fakeEmbeddingRepo) DeleteEmbedding(blogID primitive.ObjectID) error {
+	delete(r.saved, blogID)
+	return nil
+}
+
+func (r *fakeEmbeddingRepo) GetEmbeddings(model string) ([]*domain.BlogEmbedding, error) {
+	var out []*domain.BlogEmbedding
+	for _, e := range r.saved {
+		if e.Model == model {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// fakeSemanticBlogRepo serves the two blog queries semantic search makes
+type fakeSemanticBlogRepo struct {
+	domain.BlogRepository
+	blogs []*domain.Blog
+}
+
+func (r *fakeSemanticBlogRepo) GetAllBlogs(page domain.PageRequest) (*domain.Page[*domain.Blog], error) {
+	var visible []*domain.Blog
+	for _, b := range r.blogs {
+		if b.Status != domain.BlogStatusHidden {
+			visible = append(visible, b)
+		}
+	}
+	return &domain.Page[*domain.Blog]{Items: visible, Limit: page.Limit}, nil
+}
+
+func (r *fakeSemanticBlogRepo) GetBlogsByIDs(ids []primitive.ObjectID) ([]*domain.Blog, error) {
+	var out []*domain.Blog
+	for _, b := range r.blogs {
+		for _, id := range ids {
+			if b.ID == id && b.Status != domain.BlogStatusHidden {
+				out = append(out, b)
+			}
+		}
+	}
+	return out, nil
+}
+
+func newSemanticFixture() (*SemanticUsecase, *fakeEmbedder, *fakeSemanticBlogRepo) {
+	blogs := &fakeSemanticBlogRepo{blogs: []*domain.Blog{
+		{ID: primitive.NewObjectID(), Title: "Sourdough basics", Content: "bread bread flour"},
+		{ID: primitive.NewObjectID(), Title: "Marathon training", Content: "running running pace"},
+		{ID: primitive.NewObjectID(), Title: "Trail shoes", Content: "running shoes"},
+	}}
+	embedder := &fakeEmbedder{topics: []string{"bread", "running", "shoes"}}
+	repo := &fakeEmbeddingRepo{saved: map[primitive.ObjectID]*domain.BlogEmbedding{}}
+	return NewSemanticUsecase(blogs, repo, embedder, nil), embedder, blogs
+}
+
+func TestSemanticReindexSkipsCurrentVectors(t *testing.T) {
+	u, embedder, blogs := newSemanticFixture()
+
+	count, err := u.Reindex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(blogs.blogs) {
+		t.Fatalf("embedded %d blogs, want %d", count, len(blogs.blogs))
+	}
+
+	embedder.texts = 0
+	if count, err = u.Reindex(); err != nil || count != 0 || embedder.texts != 0 {
+		t.Fatalf("second reindex embedded %d blogs (%d texts), err %v", count, embedder.texts, err)
+	}
+
+	blogs.blogs[0].Content = "running"
+	if count, _ = u.Reindex(); count != 1 {
+		t.Fatalf("edited blog: embedded %d, want 1", count)
+	}
+}
+
+func TestSemanticSearchRanksByMeaning(t *testing.T) {
+	u, embedder, blogs := newSemanticFixture()
+	if _, err := u.Reindex(); err != nil {
+		t.Fatal(err)
+	}
+
+	embedder.calls = 0
+	hits, err := u.Search("running", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embedder.calls != 1 {
+		t.Fatalf("search made %d embedding calls, want 1", embedder.calls)
+	}
+	if len(hits) != 3 || hits[0].Blog.ID != blogs.blogs[1].ID || hits[1].Blog.ID != blogs.blogs[2].ID {
+		t.Fatalf("unexpected ranking: %+v", hits)
+	}
+	if !(hits[0].Score > hits[1].Score && hits[1].Score > hits[2].Score) {
+		t.Fatalf("scores out of order: %v, %v, %v", hits[0].Score, hits[1].Score, hits[2].Score)
+	}
+}
+
+func TestSemanticSearchSkipsHiddenBlogs(t *testing.T) {
+	u, _, blogs := newSemanticFixture()
+	if _, err := u.Reindex(); err != nil {
+		t.Fatal(err)
+	}
+	blogs.blogs[1].Status = domain.BlogStatusHidden
+
+	hits, err := u.Search("running", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hit := range hits {
+		if hit.Blog.ID == blogs.blogs[1].ID {
+			t.Fatal("hidden blog returned")
+		}
+	}
+}
+
+func TestSemanticSearchRejectsEmptyQuery(t *testing.T) {
+	u, embedder, _ := newSemanticFixture()
+	if _, err := u.Search("   ", 10); !errors.Is(err, domain.ErrEmptySearchQuery) {
+		t.Fatalf("err = %v, want ErrEmptySearchQuery", err)
+	}
+	if embedder.calls != 0 {
+		t.Fatal("empty query reached the embedder")
+	}
+}
+
+func TestSemanticRejectsBrokenEmbeddings(t *testing.T) {
+	u, embedder, _ := newSemanticFixture()
+	embedder.drop = 1
+	if _, err := u.Reindex(); err == nil {
+		t.Fatal("reindex accepted fewer vectors than blogs")
+	}
+	if _, err := u.Search("running", 10); err == nil {
+		t.Fatal("search accepted a missing query vector")
+	}
+
+	embedder.drop, embedder.topics = 0, nil
+	if _, err := u.Reindex(); err == nil {
+		t.Fatal("reindex accepted empty vectors")
+	}
+	if _, err := u.Search("running", 10); err == nil {
+		t.Fatal("search accepted an empty query vector")
+	}
+}
@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"net/url"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sitePages builds the addresses of the pages readers browse. A frontend configured with
+// SITE_URL serves posts, authors and tags under /blog/:id, /authors/:id and /tags/:tag; without
+// one, posts and the post list point at the API and authors and tags have no page of their own.
+type sitePages struct {
+	apiURL  string
+	siteURL string // empty when no frontend is configured
+}
+
+func newSitePages(apiURL, siteURL string) sitePages {
+	return sitePages{apiURL: strings.TrimRight(apiURL, "/"), siteURL: strings.TrimRight(siteURL, "/")}
+}
+
+func (p sitePages) home() string {
+	if p.siteURL == "" {
+		return p.apiURL + "/blog/"
+	}
+	return p.siteURL + "/"
+}
+
+func (p sitePages) post(id primitive.ObjectID) string {
+	if p.siteURL == "" {
+		return p.apiURL + "/blog/" + id.Hex()
+	}
+	return p.siteURL + "/blog/" + id.Hex()
+}
+
+// author is the author's page, false when there is none
+func (p sitePages) author(id primitive.ObjectID) (string, bool) {
+	if p.siteURL == "" {
+		return "", false
+	}
+	return p.siteURL + "/authors/" + id.Hex(), true
+}
+
+// tag is the tag's page, false when there is none
+func (p sitePages) tag(tag string) (string, bool) {
+	if p.siteURL == "" {
+		return "", false
+	}
+	return p.siteURL + "/tags/" + url.PathEscape(tag), true
+}
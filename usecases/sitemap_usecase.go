@@ -0,0 +1,388 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// posts read per query while loading the index
+const sitemapScanBatch = 1000
+
+// robots.txt served when none is configured: crawl everything but accounts and private endpoints
+const defaultRobots = `User-agent: *
+Disallow: /admin/
+Disallow: /auth/
+Disallow: /me/
+Disallow: /newsletter/
+Disallow: /events
+Disallow: /ws
+`
+
+// sitemapPost is what the sitemap keeps of a published post
+type sitemapPost struct {
+	id       primitive.ObjectID
+	author   primitive.ObjectID
+	tags     []string
+	modified time.Time
+}
+
+type sitemapEntry struct {
+	loc      string
+	modified time.Time
+}
+
+// SitemapUsecase keeps an in-memory index of published posts, updated from blog events rather
+// than by rescanning, and renders sitemaps from it. Rendered files are kept until a change
+// touches them; past domain.MaxSitemapURLs URLs /sitemap.xml becomes an index of numbered files.
+type SitemapUsecase struct {
+	blogRepo  domain.BlogRepository
+	baseURL   string // where the sitemaps themselves are served
+	pages     sitePages
+	robots    string
+	chunkSize int
+
+	mu      sync.Mutex
+	loaded  bool
+	posts   []sitemapPost // sorted by id, so new posts land in the last file
+	authors map[primitive.ObjectID]int
+	tags    map[string]int
+	docs    map[string]*domain.SitemapDocument
+}
+
+// constructor for SitemapUsecase; siteURL is the frontend serving post, author and tag pages
+// (author and tag pages are left out without one) and robots replaces the default robots.txt
+// rules when not empty
+func NewSitemapUsecase(blogRepo domain.BlogRepository, baseURL, siteURL, robots string, events domain.EventBus) *SitemapUsecase {
+	u := &SitemapUsecase{
+		blogRepo:  blogRepo,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		pages:     newSitePages(baseURL, siteURL),
+		robots:    robots,
+		chunkSize: domain.MaxSitemapURLs,
+		docs:      map[string]*domain.SitemapDocument{},
+	}
+	if events != nil {
+		update := func(e domain.Event) { u.apply(e.BlogID, e.Blog) }
+		events.Subscribe(domain.EventBlogCreated, update)
+		events.Subscribe(domain.EventBlogUpdated, update)
+		events.Subscribe(domain.EventBlogStatusChanged, update)
+		events.Subscribe(domain.EventBlogDeleted, func(e domain.Event) { u.apply(e.BlogID, nil) })
+	}
+	return u
+}
+
+// Load reads every published post into the index; sitemaps requested before it has run load it themselves
+func (u *SitemapUsecase) Load() (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.load()
+}
+
+func (u *SitemapUsecase) load() (int, error) {
+	var posts []sitemapPost
+	after := primitive.NilObjectID
+	for {
+		blogs, err := u.blogRepo.ScanBlogs(after, sitemapScanBatch)
+		if err != nil {
+			return 0, err
+		}
+		for _, b := range blogs {
+			posts = append(posts, newSitemapPost(b))
+		}
+		if len(blogs) < sitemapScanBatch {
+			break
+		}
+		after = blogs[len(blogs)-1].ID
+	}
+
+	u.posts = posts
+	u.authors = map[primitive.ObjectID]int{}
+	u.tags = map[string]int{}
+	for _, p := range posts {
+		u.count(p, 1)
+	}
+	u.docs = map[string]*domain.SitemapDocument{}
+	u.loaded = true
+	return len(posts), nil
+}
+
+func newSitemapPost(b *domain.Blog) sitemapPost {
+	return sitemapPost{id: b.ID, author: b.UserID, tags: b.Tags, modified: b.LastModified()}
+}
+
+// count adds or removes a post's author and tags from the pages the sitemap lists
+func (u *SitemapUsecase) count(p sitemapPost, delta int) {
+	if u.authors[p.author] += delta; u.authors[p.author] <= 0 {
+		delete(u.authors, p.author)
+	}
+	for _, tag := range p.tags {
+		if u.tags[tag] += delta; u.tags[tag] <= 0 {
+			delete(u.tags, tag)
+		}
+	}
+}
+
+// apply updates one post in the index; a nil or hidden blog is removed
+func (u *SitemapUsecase) apply(id primitive.ObjectID, blog *domain.Blog) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// the first load reads the current state anyway
+	if !u.loaded {
+		return
+	}
+
+	wasIndexed := u.indexed()
+	i := sort.Search(len(u.posts), func(i int) bool { return bytes.Compare(u.posts[i].id[:], id[:]) >= 0 })
+	found := i < len(u.posts) && u.posts[i].id == id
+	visible := blog != nil && blog.Status != domain.BlogStatusHidden
+
+	switch {
+	case visible && found:
+		u.count(u.posts[i], -1)
+		u.posts[i] = newSitemapPost(blog)
+		u.count(u.posts[i], 1)
+		u.invalidate(i, false)
+	case visible:
+		u.posts = slices.Insert(u.posts, i, newSitemapPost(blog))
+		u.count(u.posts[i], 1)
+		u.invalidate(i, true)
+	case found:
+		u.count(u.posts[i], -1)
+		u.posts = slices.Delete(u.posts, i, i+1)
+		u.invalidate(i, true)
+	default:
+		return
+	}
+
+	// switching between a single sitemap and an index renames every file
+	if u.indexed() != wasIndexed {
+		u.docs = map[string]*domain.SitemapDocument{}
+	}
+}
+
+// invalidate drops the rendered files a change to the post at position i affects: the file
+// listing it, every later post file when posts shifted, and the author, tag and top-level files
+func (u *SitemapUsecase) invalidate(i int, shifted bool) {
+	first := i/u.chunkSize + 1
+	for name := range u.docs {
+		if kind, n := parseSitemapName(name); kind == "posts" && (n < first || (n > first && !shifted)) {
+			continue
+		}
+		delete(u.docs, name)
+	}
+}
+
+func (u *SitemapUsecase) urlCount() int {
+	if u.pages.siteURL == "" {
+		return len(u.posts)
+	}
+	return len(u.posts) + len(u.authors) + len(u.tags)
+}
+
+func (u *SitemapUsecase) indexed() bool {
+	return u.urlCount() > u.chunkSize
+}
+
+// Document returns /sitemap.xml for name "sitemap.xml", or one of the files it indexes
+// (posts-1.xml, authors-1.xml, tags-1.xml, ...) once the site outgrows a single sitemap
+func (u *SitemapUsecase) Document(name string) (*domain.SitemapDocument, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.loaded {
+		if _, err := u.load(); err != nil {
+			return nil, err
+		}
+	}
+	if name != "sitemap.xml" && !u.indexed() {
+		return nil, domain.ErrSitemapNotFound
+	}
+	if doc, ok := u.docs[name]; ok {
+		return doc, nil
+	}
+
+	sections := map[string][]sitemapEntry{
+		"posts":   u.postEntries(),
+		"authors": u.authorEntries(),
+		"tags":    u.tagEntries(),
+	}
+
+	var (
+		body []byte
+		err  error
+	)
+	if name == "sitemap.xml" {
+		if u.indexed() {
+			body, err = renderSitemapIndex(u.indexEntries(sections))
+		} else {
+			all := slices.Concat(sections["posts"], sections["authors"], sections["tags"])
+			body, err = renderURLSet(all)
+		}
+	} else {
+		kind, n := parseSitemapName(name)
+		entries, ok := sections[kind]
+		if !ok || n < 1 || (n-1)*u.chunkSize >= len(entries) {
+			return nil, domain.ErrSitemapNotFound
+		}
+		body, err = renderURLSet(entries[(n-1)*u.chunkSize : min(n*u.chunkSize, len(entries))])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &domain.SitemapDocument{Body: body, Modified: time.Now()}
+	u.docs[name] = doc
+	return doc, nil
+}
+
+// Robots returns robots.txt, always pointing crawlers at the sitemap
+func (u *SitemapUsecase) Robots() string {
+	rules := u.robots
+	if strings.TrimSpace(rules) == "" {
+		rules = defaultRobots
+	}
+	rules = strings.TrimRight(rules, "\n") + "\n"
+	if !strings.Contains(strings.ToLower(rules), "sitemap:") {
+		rules += "\nSitemap: " + u.baseURL + "/sitemap.xml\n"
+	}
+	return rules
+}
+
+func (u *SitemapUsecase) postEntries() []sitemapEntry {
+	entries := make([]sitemapEntry, len(u.posts))
+	for i, p := range u.posts {
+		entries[i] = sitemapEntry{loc: u.pages.post(p.id), modified: p.modified}
+	}
+	return entries
+}
+
+// author pages change whenever one of their posts does
+func (u *SitemapUsecase) authorEntries() []sitemapEntry {
+	latest := map[primitive.ObjectID]time.Time{}
+	for _, p := range u.posts {
+		if p.modified.After(latest[p.author]) {
+			latest[p.author] = p.modified
+		}
+	}
+	ids := make([]primitive.ObjectID, 0, len(latest))
+	for id := range latest {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+
+	entries := []sitemapEntry{}
+	for _, id := range ids {
+		if loc, ok := u.pages.author(id); ok {
+			entries = append(entries, sitemapEntry{loc: loc, modified: latest[id]})
+		}
+	}
+	return entries
+}
+
+// tag pages change whenever one of their posts does
+func (u *SitemapUsecase) tagEntries() []sitemapEntry {
+	latest := map[string]time.Time{}
+	for _, p := range u.posts {
+		for _, tag := range p.tags {
+			if p.modified.After(latest[tag]) {
+				latest[tag] = p.modified
+			}
+		}
+	}
+	tags := make([]string, 0, len(latest))
+	for tag := range latest {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	entries := []sitemapEntry{}
+	for _, tag := range tags {
+		if loc, ok := u.pages.tag(tag); ok {
+			entries = append(entries, sitemapEntry{loc: loc, modified: latest[tag]})
+		}
+	}
+	return entries
+}
+
+// indexEntries lists the numbered files of every section, each dated by its newest entry
+func (u *SitemapUsecase) indexEntries(sections map[string][]sitemapEntry) []sitemapEntry {
+	var files []sitemapEntry
+	for _, kind := range []string{"posts", "authors", "tags"} {
+		entries := sections[kind]
+		for start := 0; start < len(entries); start += u.chunkSize {
+			file := sitemapEntry{loc: u.baseURL + "/sitemaps/" + kind + "-" + strconv.Itoa(start/u.chunkSize+1) + ".xml"}
+			for _, e := range entries[start:min(start+u.chunkSize, len(entries))] {
+				if e.modified.After(file.modified) {
+					file.modified = e.modified
+				}
+			}
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// parseSitemapName splits "posts-2.xml" into its section and number
+func parseSitemapName(name string) (string, int) {
+	kind, number, ok := strings.Cut(strings.TrimSuffix(name, ".xml"), "-")
+	if !ok || !strings.HasSuffix(name, ".xml") {
+		return "", 0
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return "", 0
+	}
+	return kind, n
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+func renderURLSet(entries []sitemapEntry) ([]byte, error) {
+	return marshalSitemap(sitemapURLSet{URLs: sitemapURLs(entries)})
+}
+
+func renderSitemapIndex(entries []sitemapEntry) ([]byte, error) {
+	return marshalSitemap(sitemapIndex{Sitemaps: sitemapURLs(entries)})
+}
+
+func sitemapURLs(entries []sitemapEntry) []sitemapURL {
+	urls := make([]sitemapURL, len(entries))
+	for i, e := range entries {
+		urls[i] = sitemapURL{Loc: e.loc}
+		if !e.modified.IsZero() {
+			urls[i].LastMod = e.modified.UTC().Format(time.RFC3339)
+		}
+	}
+	return urls
+}
+
+func marshalSitemap(doc any) ([]byte, error) {
+	body, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
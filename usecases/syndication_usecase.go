@@ -0,0 +1,172 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// feed size, excerpt length and how long a built feed is served from memory
+const (
+	feedMaxItems      = 50
+	feedExcerptLength = 300
+	feedCacheTTL      = 10 * time.Minute
+)
+
+// SyndicationUsecase builds RSS, Atom and JSON feeds of published posts, for the whole
+// blog, one author or one tag
+type SyndicationUsecase struct {
+	blogRepo domain.BlogRepository
+	userRepo domain.UserRepository
+	title    string
+	baseURL  string // where the feeds are served
+	pages    sitePages
+	renderer domain.FeedRenderer
+	cache    *ttlCache[string, *domain.Feed]
+
+	mu sync.Mutex
+	// posts leaving or returning to feeds do not show in the dates of the remaining items,
+	// so feeds are never older than the last such change (or than the process)
+	changedAt time.Time
+}
+
+// constructor for SyndicationUsecase; title names the site, baseURL is where the feeds are
+// served and siteURL the frontend their links point to, if any
+func NewSyndicationUsecase(blogRepo domain.BlogRepository, userRepo domain.UserRepository, renderer domain.FeedRenderer, title, baseURL, siteURL string, events domain.EventBus) *SyndicationUsecase {
+	u := &SyndicationUsecase{
+		blogRepo:  blogRepo,
+		userRepo:  userRepo,
+		title:     title,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		pages:     newSitePages(baseURL, siteURL),
+		renderer:  renderer,
+		cache:     newTTLCache[string, *domain.Feed](feedCacheTTL),
+		changedAt: time.Now(),
+	}
+	if events != nil {
+		events.Subscribe(domain.EventBlogCreated, func(e domain.Event) { u.cache.Clear() })
+		events.Subscribe(domain.EventBlogUpdated, func(e domain.Event) { u.cache.Clear() })
+		changed := func(e domain.Event) {
+			u.mu.Lock()
+			u.changedAt = time.Now()
+			u.mu.Unlock()
+			u.cache.Clear()
+		}
+		events.Subscribe(domain.EventBlogDeleted, changed)
+		events.Subscribe(domain.EventBlogStatusChanged, changed)
+	}
+	return u
+}
+
+// GetFeed returns the newest published posts, optionally of one author or tag, with the
+// full text or an excerpt of each
+func (u *SyndicationUsecase) GetFeed(authorID, tag, mode string) (*domain.Feed, error) {
+	if mode == "" {
+		mode = domain.FeedContentFull
+	}
+	if mode != domain.FeedContentFull && mode != domain.FeedContentExcerpt {
+		return nil, domain.ErrInvalidFeedMode
+	}
+
+	key := authorID + "\x00" + tag + "\x00" + mode
+	if feed, ok := u.cache.Get(key); ok {
+		return feed, nil
+	}
+
+	feed := &domain.Feed{Title: u.title, Description: "New posts on " + u.title, Link: u.pages.home(), URL: u.baseURL + "/feed"}
+	var author *primitive.ObjectID
+	switch {
+	case authorID != "":
+		aid, err := primitive.ObjectIDFromHex(authorID)
+		if err != nil {
+			return nil, err
+		}
+		user, err := u.userRepo.GetByID(aid)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		author = &aid
+		feed.Title = u.title + ": posts by " + user.Username
+		feed.Description = "New posts by " + user.Username + " on " + u.title
+		feed.URL = u.baseURL + "/authors/" + aid.Hex() + "/feed"
+		// without an author page the feed links to the whole site
+		if page, ok := u.pages.author(aid); ok {
+			feed.Link = page
+		}
+	case tag != "":
+		var err error
+		if tag, err = followTag(tag); err != nil {
+			return nil, err
+		}
+		feed.Title = u.title + ": posts tagged " + tag
+		feed.Description = "New posts tagged " + tag + " on " + u.title
+		feed.URL = u.baseURL + "/tags/" + url.PathEscape(tag) + "/feed"
+		if page, ok := u.pages.tag(tag); ok {
+			feed.Link = page
+		}
+	}
+
+	if mode != domain.FeedContentFull {
+		feed.Query = "?mode=" + mode
+	}
+
+	blogs, err := u.blogRepo.GetRecentBlogs(author, tag, time.Time{}, feedMaxItems)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	feed.Updated = u.changedAt
+	u.mu.Unlock()
+
+	names := map[primitive.ObjectID]string{}
+	feed.Items = make([]*domain.FeedItem, 0, len(blogs))
+	for _, b := range blogs {
+		item := &domain.FeedItem{
+			Title:     b.Title,
+			Link:      u.pages.post(b.ID),
+			Author:    u.authorName(b, names),
+			Tags:      b.Tags,
+			Content:   b.Content,
+			Published: b.DateCreated,
+			Updated:   b.LastModified(),
+		}
+		if mode == domain.FeedContentExcerpt {
+			item.Content = excerpt(b.Content, feedExcerptLength)
+			item.Excerpt = true
+		}
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	u.cache.Set(key, feed)
+	return feed, nil
+}
+
+// RenderFeed serializes a feed from GetFeed and returns it with its content type
+func (u *SyndicationUsecase) RenderFeed(feed *domain.Feed, format string) ([]byte, string, error) {
+	return u.renderer.RenderFeed(feed, format)
+}
+
+// authorName prefers the denormalized name and looks up posts written before it existed
+func (u *SyndicationUsecase) authorName(b *domain.Blog, names map[primitive.ObjectID]string) string {
+	if b.AuthorName != "" {
+		return b.AuthorName
+	}
+	name, ok := names[b.UserID]
+	if !ok {
+		name = "unknown"
+		if user, err := u.userRepo.GetByID(b.UserID); err == nil {
+			name = user.Username
+		}
+		names[b.UserID] = name
+	}
+	return name
+}
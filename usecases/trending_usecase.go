@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// engagement weights: a comment is worth two likes, ten views are worth one like
+const (
+	trendingLikeWeight    = 1.0
+	trendingDislikeWeight = 1.0
+	trendingCommentWeight = 2.0
+	trendingViewWeight    = 0.1
+)
+
+// trendingWindows maps each window to how far back it looks and the half-life of its decay
+var trendingWindows = map[string]struct {
+	span     time.Duration
+	halfLife time.Duration
+}{
+	domain.TrendingDay:   {span: 24 * time.Hour, halfLife: 6 * time.Hour},
+	domain.TrendingWeek:  {span: 7 * 24 * time.Hour, halfLife: 36 * time.Hour},
+	domain.TrendingMonth: {span: 30 * 24 * time.Hour, halfLife: 7 * 24 * time.Hour},
+}
+
+// TrendingUsecase periodically recomputes time-decayed trending scores
+type TrendingUsecase struct {
+	blogRepo    domain.BlogRepository
+	commentRepo domain.CommentRepository
+}
+
+func NewTrendingUsecase(blogRepo domain.BlogRepository, commentRepo domain.CommentRepository) *TrendingUsecase {
+	return &TrendingUsecase{blogRepo: blogRepo, commentRepo: commentRepo}
+}
+
+// TrendingScore weighs engagement and halves it every halfLife of the post's age, so a
+// post needs steady new engagement to stay on top
+func TrendingScore(likes, dislikes, views, comments int, age, halfLife time.Duration) float64 {
+	engagement := trendingLikeWeight*float64(likes) -
+		trendingDislikeWeight*float64(dislikes) +
+		trendingCommentWeight*float64(comments) +
+		trendingViewWeight*float64(views)
+	if engagement <= 0 {
+		return 0
+	}
+	if age < 0 {
+		age = 0
+	}
+	decay := math.Pow(0.5, age.Hours()/halfLife.Hours())
+	return math.Round(engagement*decay*1e6) / 1e6
+}
+
+// trendingScanBatch is how many posts are scored per bulk write
+const trendingScanBatch = 500
+
+// Recompute scores the visible blogs young enough for the longest window, one batch at a
+// time, and zeroes the scores of older ones
+func (u *TrendingUsecase) Recompute() (int, error) {
+	now := time.Now()
+	since := now.Add(-trendingWindows[domain.TrendingMonth].span)
+	count := 0
+	after := primitive.NilObjectID
+	for {
+		blogs, err := u.blogRepo.ScanRecentBlogs(since, after, trendingScanBatch)
+		if err != nil {
+			return count, err
+		}
+
+		ids := make([]primitive.ObjectID, 0, len(blogs))
+		for _, b := range blogs {
+			ids = append(ids, b.ID)
+		}
+		comments, err := u.commentRepo.CountByBlogs(ids, domain.CommentStatusApproved)
+		if err != nil {
+			return count, err
+		}
+
+		scores := make(map[primitive.ObjectID]domain.TrendingScores, len(blogs))
+		for _, b := range blogs {
+			age := now.Sub(b.DateCreated)
+			score := func(window string) float64 {
+				return TrendingScore(b.Likes, b.Dislikes, b.ViewCount, comments[b.ID], age, trendingWindows[window].halfLife)
+			}
+			scores[b.ID] = domain.TrendingScores{
+				Day:       score(domain.TrendingDay),
+				Week:      score(domain.TrendingWeek),
+				Month:     score(domain.TrendingMonth),
+				UpdatedAt: now,
+			}
+		}
+		if err := u.blogRepo.UpdateTrendingScores(scores); err != nil {
+			return count, err
+		}
+		count += len(scores)
+
+		if len(blogs) < trendingScanBatch {
+			break
+		}
+		after = blogs[len(blogs)-1].ID
+	}
+
+	reset, err := u.blogRepo.ResetTrendingScores(since)
+	return count + reset, err
+}
+
+// Run recomputes scores right away and then every interval until stop is closed
+func (u *TrendingUsecase) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := u.Recompute(); err != nil {
+			log.Println("trending recompute failed:", err)
+		} else {
+			log.Printf("trending scores recomputed for %d blogs", n)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
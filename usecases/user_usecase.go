@@ -9,11 +9,12 @@ import (
 )
 
 type UserUsecase struct {
-	repo domain.UserRepository
+	repo   domain.UserRepository
+	events domain.EventBus
 }
 
-func NewUserUsecase(repo domain.UserRepository) *UserUsecase {
-	return &UserUsecase{repo: repo}
+func NewUserUsecase(repo domain.UserRepository, events domain.EventBus) *UserUsecase {
+	return &UserUsecase{repo: repo, events: events}
 }
 
 func (u *UserUsecase) CreateUser(user *domain.User) (primitive.ObjectID, error) {
@@ -28,15 +29,19 @@ func (u *UserUsecase) GetUserByID(id primitive.ObjectID) (*domain.User, error) {
 	return u.repo.GetByID(id)
 }
 
-func (u *UserUsecase) GetAllUsers() ([]*domain.User, error) {
-	return u.repo.GetAll()
+func (u *UserUsecase) GetAllUsers(page domain.PageRequest) (*domain.Page[*domain.User], error) {
+	return u.repo.GetAll(page.Normalize())
 }
 
 func (u *UserUsecase) PromoteUser(userID primitive.ObjectID, newRole string) error {
 	if newRole == "" {
 		return errors.New("new role must be provided")
 	}
-	return u.repo.PromoteUser(userID, newRole)
+	if err := u.repo.PromoteUser(userID, newRole); err != nil {
+		return err
+	}
+	publish(u.events, domain.Event{Type: domain.EventUserRoleChanged, Payload: domain.RoleChange{UserID: userID, Role: newRole}})
+	return nil
 }
 
 func (u *UserUsecase) DeleteUserByID(id primitive.ObjectID) error {
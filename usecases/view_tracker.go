@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"Blog/domain"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// user agent fragments of crawlers, link previewers and scripted clients
+var botSignatures = []string{
+	"bot", "crawler", "spider", "slurp", "crawling", "headless", "lighthouse",
+	"facebookexternalhit", "embedly", "preview", "curl", "wget", "python-requests",
+	"go-http-client", "okhttp", "httpclient", "java/", "libwww", "scrapy", "postman",
+}
+
+// isBot reports whether a user agent looks automated; a missing one counts as automated
+func isBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, sig := range botSignatures {
+		if strings.Contains(ua, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// ViewTracker counts a blog view at most once per viewer per window and buffers the
+// increments in memory until the next flush
+type ViewTracker struct {
+	repo   domain.BlogRepository
+	window time.Duration
+
+	mu      sync.Mutex
+	seen    map[string]time.Time // blog id + viewer key -> when the view was counted
+	pending map[primitive.ObjectID]int
+}
+
+func NewViewTracker(repo domain.BlogRepository, window time.Duration) *ViewTracker {
+	return &ViewTracker{
+		repo:    repo,
+		window:  window,
+		seen:    map[string]time.Time{},
+		pending: map[primitive.ObjectID]int{},
+	}
+}
+
+// viewerKey is the user id for signed-in readers and a hash of IP and user agent otherwise,
+// so raw addresses are never kept
+func viewerKey(v domain.Viewer) string {
+	if v.UserID != "" {
+		return "u:" + v.UserID
+	}
+	sum := sha256.Sum256([]byte(v.IP + "|" + v.UserAgent))
+	return "a:" + hex.EncodeToString(sum[:12])
+}
+
+// Record counts the view unless it comes from a bot or the viewer was already counted
+// within the window; it reports whether the view was counted
+func (t *ViewTracker) Record(blogID primitive.ObjectID, viewer domain.Viewer) bool {
+	if isBot(viewer.UserAgent) {
+		return false
+	}
+	key := blogID.Hex() + "|" + viewerKey(viewer)
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if last, ok := t.seen[key]; ok && now.Sub(last) < t.window {
+		return false
+	}
+	t.seen[key] = now
+	t.pending[blogID]++
+	return true
+}
+
+// Flush writes buffered increments and forgets viewers whose window has passed
+func (t *ViewTracker) Flush() error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = map[primitive.ObjectID]int{}
+	now := time.Now()
+	for key, at := range t.seen {
+		if now.Sub(at) >= t.window {
+			delete(t.seen, key)
+		}
+	}
+	t.mu.Unlock()
+
+	if err := t.repo.AddViewCounts(pending); err != nil {
+		// put the counts back so the next flush retries them
+		t.mu.Lock()
+		for id, n := range pending {
+			t.pending[id] += n
+		}
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until stop is closed, flushing once more on the way out
+func (t *ViewTracker) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.Flush(); err != nil {
+				log.Println("view count flush failed:", err)
+			}
+		case <-stop:
+			if err := t.Flush(); err != nil {
+				log.Println("view count flush failed:", err)
+			}
+			return
+		}
+	}
+}